@@ -0,0 +1,115 @@
+// Command golist runs the tasks in a YAML or JSON task file
+// (see the loader package for the format) and displays their
+// progress as they run.
+//
+// Usage:
+//
+//	golist [flags] <file>
+//
+// Ctrl-C cancels the running tasks and a second Ctrl-C exits
+// straight away. golist exits with status 1 if any task fails,
+// and with status 2 if the task file can't be loaded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/a-poor/golist"
+	"github.com/a-poor/golist/loader"
+)
+
+// displayModes maps the -display flag's values to DisplayModes
+var displayModes = map[string]golist.DisplayMode{
+	"auto":     golist.DisplayAuto,
+	"terminal": golist.DisplayTerminal,
+	"log":      golist.DisplayLog,
+	"json":     golist.DisplayJSON,
+	"tap":      golist.DisplayTAP,
+}
+
+// outputModes maps the -output flag's values to TaskOutputModes
+var outputModes = map[string]golist.TaskOutputMode{
+	"passthrough": golist.OutputPassthrough,
+	"tail":        golist.OutputTail,
+	"failure":     golist.OutputOnFailure,
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run runs golist and returns its exit status
+func run() int {
+	display := flag.String("display", "auto", "how to display the run: auto, terminal, log, json, or tap")
+	summary := flag.Bool("summary", false, "print a summary after the run")
+	elapsed := flag.Bool("elapsed", false, "show how long each task has been running")
+	output := flag.String("output", "passthrough", "what to do with the commands' output: passthrough, tail, or failure")
+	junit := flag.String("junit", "", "write a JUnit XML report to `file` after the run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file>\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return 2
+	}
+
+	mode, ok := displayModes[*display]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "golist: unknown display mode %q\n", *display)
+		return 2
+	}
+
+	outputMode, ok := outputModes[*output]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "golist: unknown output mode %q\n", *output)
+		return 2
+	}
+
+	l, err := loader.Load(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golist: %s\n", err)
+		return 2
+	}
+	l.Display = mode
+	l.ShowSummary = *summary
+	l.ShowElapsed = *elapsed
+	l.TaskOutput = outputMode
+
+	var report *golist.JUnitReporter
+	if *junit != "" {
+		report = golist.NewJUnitReporter(flag.Arg(0))
+		l.AddObserver(report)
+	}
+
+	// Stop the running tasks on Ctrl-C, or exit on a second Ctrl-C
+	l.HandleSignals = true
+	runErr := l.RunAndWait()
+
+	if report != nil {
+		if err := writeReport(report, l, *junit); err != nil {
+			fmt.Fprintf(os.Stderr, "golist: %s\n", err)
+			return 1
+		}
+	}
+	if runErr != nil {
+		return 1
+	}
+	return 0
+}
+
+// writeReport writes the JUnit report for the List `l` to `path`
+func writeReport(r *golist.JUnitReporter, l *golist.List, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f, l); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,273 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// CommandTask is a Task that runs a command, like a shell
+// script or a build tool, as its action.
+//
+// While the command runs, the last line of its output is shown
+// as the task's message. If the command fails, its output is
+// printed (through the TaskContext's Println) and, if it exited
+// with a non-zero exit code, the task's error is an *ExitError.
+//
+// If the List's TaskOutput isn't OutputPassthrough, each line of
+// output is passed to the TaskContext's Println as it arrives
+// instead, so it's kept (and shown) like any other printed line.
+//
+// If the task's context is cancelled (or it times out), the
+// command's whole process group is killed.
+type CommandTask struct {
+	*Task
+	Name string   // The command to run
+	Args []string // The command's arguments
+	Dir  string   // The command's working directory (defaults to the current directory)
+	Env  []string // Extra environment variables for the command ("KEY=value"), added to the current environment
+
+	mu     sync.RWMutex // Guards the command's output
+	stdout []byte       // The standard output from the last run
+	stderr []byte       // The standard error from the last run
+}
+
+// NewCommandTask creates a CommandTask that runs
+// the command `name` with the arguments `args`
+func NewCommandTask(message string, name string, args ...string) *CommandTask {
+	ct := &CommandTask{
+		Name: name,
+		Args: args,
+	}
+	ct.Task = NewTask(message, ct.run)
+	ct.Task.owner = ct
+	return ct
+}
+
+// ExitError is the error from a CommandTask whose
+// command exited with a non-zero exit code
+type ExitError struct {
+	Command  string          // The command line that was run
+	ExitCode int             // The command's exit code
+	Err      *exec.ExitError // The underlying error from os/exec
+}
+
+// Error returns the command and its exit code
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s: exit status %d", e.Command, e.ExitCode)
+}
+
+// Unwrap returns the underlying *exec.ExitError
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Stdout returns the standard output from the command's last run
+func (ct *CommandTask) Stdout() []byte {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.stdout
+}
+
+// Stderr returns the standard error from the command's last run
+func (ct *CommandTask) Stderr() []byte {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+	return ct.stderr
+}
+
+// outputMode returns the TaskOutputMode of the
+// List that the TaskContext `c` belongs to
+func outputMode(c TaskContext) TaskOutputMode {
+	if tc, ok := c.(*taskContext); ok {
+		return tc.outputMode
+	}
+	return OutputPassthrough
+}
+
+// commandLine returns the command and its arguments as one string
+func (ct *CommandTask) commandLine() string {
+	return strings.Join(append([]string{ct.Name}, ct.Args...), " ")
+}
+
+// run is the CommandTask's action. It runs the command,
+// showing its output as the task's message.
+func (ct *CommandTask) run(c TaskContext) error {
+	cmd := exec.Command(ct.Name, ct.Args...)
+	cmd.Dir = ct.Dir
+	if len(ct.Env) > 0 {
+		cmd.Env = append(os.Environ(), ct.Env...)
+	}
+	setProcessGroup(cmd)
+
+	// Keep stdout and stderr, as well as all of the output
+	// together (in order), and show the last line as it runs
+	var stdout, stderr bytes.Buffer
+	out := &outputWriter{setMessage: c.SetMessage}
+	live := outputMode(c) != OutputPassthrough
+	if live {
+		out.onLine = func(line string) { c.Println(line) }
+	}
+	cmd.Stdout = &teeWriter{&stdout, out}
+	cmd.Stderr = &teeWriter{&stderr, out}
+
+	// Restore the task's message once the command is done
+	message := ct.GetMessage()
+	defer c.SetMessage(message)
+
+	err := ct.wait(c, cmd)
+	out.flush()
+
+	ct.mu.Lock()
+	ct.stdout, ct.stderr = stdout.Bytes(), stderr.Bytes()
+	ct.mu.Unlock()
+
+	if err == nil {
+		return nil
+	}
+
+	// Print the output so it's clear why the command
+	// failed, unless it was already printed line by line
+	if !live {
+		for _, line := range out.lines() {
+			c.Println(line)
+		}
+	}
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && c.Context().Err() == nil {
+		return &ExitError{
+			Command:  ct.commandLine(),
+			ExitCode: ee.ExitCode(),
+			Err:      ee,
+		}
+	}
+	return err
+}
+
+// wait starts the command and waits for it to finish. If
+// the TaskContext's context is cancelled first, the command's
+// process group is killed and the context's error is returned.
+func (ct *CommandTask) wait(c TaskContext, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-c.Context().Done():
+		killProcessGroup(cmd)
+		<-done
+		return c.Context().Err()
+	}
+}
+
+// teeWriter writes to a buffer and then to an outputWriter
+type teeWriter struct {
+	buf *bytes.Buffer
+	out *outputWriter
+}
+
+// Write writes `p` to the buffer and the outputWriter
+func (w *teeWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	return w.out.Write(p)
+}
+
+// outputWriter collects a command's output (from both stdout
+// and stderr) and sets the task's message to each new line.
+// It's safe for concurrent use.
+type outputWriter struct {
+	setMessage func(string)
+	onLine     func(string) // If set, it's called with each complete line of output
+
+	mu          sync.Mutex
+	all         bytes.Buffer // All of the output
+	partial     []byte       // The current, unfinished line
+	overwritten []byte       // The current line's text before its last "\r"
+}
+
+// Write collects `p`, setting the task's message
+// to the last complete, non-empty line in it
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.all.Write(p)
+
+	// Lines end with "\n" or "\r" (e.g. for progress bars)
+	var last string
+	for _, b := range p {
+		if b != '\n' && b != '\r' {
+			w.partial = append(w.partial, b)
+			continue
+		}
+		if line := strings.TrimSpace(stripANSI(string(w.partial))); line != "" {
+			last = line
+		}
+
+		// A line that was overwritten using "\r" is passed
+		// on with its final text once it's finished
+		switch {
+		case b == '\r':
+			if len(w.partial) > 0 {
+				w.overwritten = append(w.overwritten[:0], w.partial...)
+			}
+		case w.onLine != nil && len(w.partial) > 0:
+			w.onLine(string(w.partial))
+		case w.onLine != nil:
+			w.onLine(string(w.overwritten))
+		}
+		if b == '\n' {
+			w.overwritten = w.overwritten[:0]
+		}
+		w.partial = w.partial[:0]
+	}
+	if last != "" {
+		w.setMessage(last)
+	}
+	return len(p), nil
+}
+
+// flush passes on the last line of output if it didn't end with
+// "\n" (or, if it's empty, the text it had before its last "\r"),
+// once the command is done
+func (w *outputWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	line := w.partial
+	if len(line) == 0 {
+		line = w.overwritten
+	}
+	if w.onLine != nil && len(line) > 0 {
+		w.onLine(string(line))
+	}
+	w.partial, w.overwritten = w.partial[:0], w.overwritten[:0]
+}
+
+// lines returns all of the output, split into lines. If
+// a line was overwritten using "\r" (e.g. by a progress
+// bar), only its final text is included.
+func (w *outputWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := strings.TrimRight(w.all.String(), "\r\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		lines[i] = line[strings.LastIndex(line, "\r")+1:]
+	}
+	return lines
+}
@@ -0,0 +1,281 @@
+package golist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// skipWithoutShell skips tests that need a POSIX shell
+func skipWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs a POSIX shell")
+	}
+}
+
+// printLog records the lines printed through a TaskContext
+type printLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (p *printLog) context(ctx context.Context) *taskContext {
+	return &taskContext{
+		ctx: ctx,
+		println: func(a ...interface{}) error {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.lines = append(p.lines, fmt.Sprint(a...))
+			return nil
+		},
+	}
+}
+
+func TestCommandTask(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("greet", "sh", "-c", "echo hello; echo oops >&2; echo $GREETING")
+	ct.Env = []string{"GREETING=bye"}
+
+	var p printLog
+	if err := ct.Run(p.context(context.Background())); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := string(ct.Stdout()); s != "hello\nbye\n" {
+		t.Errorf("expected stdout %q, got %q", "hello\nbye\n", s)
+	}
+	if s := string(ct.Stderr()); s != "oops\n" {
+		t.Errorf("expected stderr %q, got %q", "oops\n", s)
+	}
+	if len(p.lines) != 0 {
+		t.Errorf("expected nothing to be printed on success, got %q", p.lines)
+	}
+	if m := ct.GetMessage(); m != "greet" {
+		t.Errorf("expected the message to be restored, got %q", m)
+	}
+	if s := ct.GetStatus(); s != TaskCompleted {
+		t.Errorf("expected status %s, got %s", TaskCompleted, s)
+	}
+}
+
+func TestCommandTask_Dir(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	ct := NewCommandTask("pwd", "sh", "-c", "pwd -P")
+	ct.Dir = dir
+
+	if err := ct.Run(&taskContext{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s := strings.TrimSpace(string(ct.Stdout())); !strings.HasSuffix(s, strings.TrimPrefix(dir, "/private")) {
+		t.Errorf("expected the command to run in %q, got %q", dir, s)
+	}
+}
+
+func TestCommandTask_ExitError(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("fail", "sh", "-c", "echo one; echo two >&2; exit 3")
+
+	var p printLog
+	err := ct.Run(p.context(context.Background()))
+	var ee *ExitError
+	if !errors.As(err, &ee) {
+		t.Fatalf("expected an *ExitError, got %v", err)
+	}
+	if ee.ExitCode != 3 || !strings.HasPrefix(ee.Command, "sh -c") {
+		t.Errorf("unexpected exit error %+v", ee)
+	}
+	var xe *exec.ExitError
+	if !errors.As(err, &xe) {
+		t.Error("expected the error to unwrap to an *exec.ExitError")
+	}
+	// Stdout and stderr can arrive in either order
+	sort.Strings(p.lines)
+	if s := strings.Join(p.lines, ","); s != "one,two" {
+		t.Errorf("expected the output to be printed, got %q", s)
+	}
+	if s := ct.GetStatus(); s != TaskFailed {
+		t.Errorf("expected status %s, got %s", TaskFailed, s)
+	}
+}
+
+func TestCommandTask_LastLineMessage(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("build", "sh", "-c", "echo step 1; echo step 2; sleep 1")
+
+	done := make(chan error)
+	go func() {
+		done <- ct.Run(&taskContext{})
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for ct.GetMessage() != "step 2" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if m := ct.GetMessage(); m != "step 2" {
+		t.Errorf("expected the last line as the message, got %q", m)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommandTask_Cancel(t *testing.T) {
+	skipWithoutShell(t)
+
+	// The child process keeps the output open, so this would
+	// hang if only the shell was killed
+	ct := NewCommandTask("sleep", "sh", "-c", "sleep 10 & wait")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := ct.Run(&taskContext{ctx: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected the command to be killed, took %s", d)
+	}
+	if s := ct.GetStatus(); s != TaskCancelled {
+		t.Errorf("expected status %s, got %s", TaskCancelled, s)
+	}
+}
+
+func TestCommandTask_NotFound(t *testing.T) {
+	ct := NewCommandTask("missing", "golist-command-that-does-not-exist")
+	err := ct.Run(&taskContext{})
+	var ee *ExitError
+	if err == nil || errors.As(err, &ee) {
+		t.Errorf("expected a non-exit error, got %v", err)
+	}
+}
+
+func TestOutputWriter(t *testing.T) {
+	var messages []string
+	w := &outputWriter{setMessage: func(m string) {
+		messages = append(messages, m)
+	}}
+	w.Write([]byte("first\nsec"))
+	w.Write([]byte("ond\n\n"))
+	w.Write([]byte("10%\r50%\r100%\r\n"))
+
+	if s := strings.Join(messages, ","); s != "first,second,100%" {
+		t.Errorf("expected messages %q, got %q", "first,second,100%", s)
+	}
+	if s := strings.Join(w.lines(), ","); s != "first,second,,100%" {
+		t.Errorf("expected lines %q, got %q", "first,second,,100%", s)
+	}
+}
+
+func TestOutputWriter_OnLine(t *testing.T) {
+	var lines []string
+	w := &outputWriter{
+		setMessage: func(string) {},
+		onLine:     func(line string) { lines = append(lines, line) },
+	}
+	w.Write([]byte("first\nsec"))
+	w.Write([]byte("ond\n\n"))
+	w.Write([]byte("10%\r50%\r100%\r\n"))
+	w.Write([]byte("unfinished"))
+
+	if s := strings.Join(lines, ","); s != "first,second,,100%" {
+		t.Errorf("expected lines %q, got %q", "first,second,,100%", s)
+	}
+
+	// The unfinished line is passed on once the output's done
+	w.flush()
+	w.Write([]byte("90%\r"))
+	w.flush()
+	w.flush()
+	if s := strings.Join(lines, ","); s != "first,second,,100%,unfinished,90%" {
+		t.Errorf("expected lines %q, got %q", "first,second,,100%,unfinished,90%", s)
+	}
+}
+
+func TestCommandTask_LiveOutput(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("fail", "sh", "-c", "echo one; echo two; exit 1")
+
+	var p printLog
+	c := p.context(context.Background())
+	c.outputMode = OutputOnFailure
+	if err := ct.Run(c); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// Each line is kept as it arrives, and they're
+	// printed together once the command fails
+	if out := ct.getOutput(); strings.Join(out, ",") != "one,two" {
+		t.Errorf("expected output %q, got %q", "one,two", out)
+	}
+	if len(p.lines) != 1 || p.lines[0] != "Output from fail:\n  one\n  two" {
+		t.Errorf("expected the output to be printed once, together, got %q", p.lines)
+	}
+}
+
+func TestCommandTask_LiveOutputNoNewline(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("fail", "sh", "-c", "echo first; printf 'fatal: no newline'; exit 3")
+
+	var p printLog
+	c := p.context(context.Background())
+	c.outputMode = OutputOnFailure
+	if err := ct.Run(c); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The last line is kept, even without a newline
+	if out := ct.getOutput(); strings.Join(out, ",") != "first,fatal: no newline" {
+		t.Errorf("expected output %q, got %q", "first,fatal: no newline", out)
+	}
+	if len(p.lines) != 1 || !strings.HasSuffix(p.lines[0], "\n  fatal: no newline") {
+		t.Errorf("expected the last line to be printed, got %q", p.lines)
+	}
+}
+
+func TestCommandTask_Events(t *testing.T) {
+	skipWithoutShell(t)
+	ct := NewCommandTask("greet", "sh", "-c", "echo hello")
+
+	l := NewListWithWriter(&strings.Builder{})
+	l.Display = DisplayLog
+	l.TaskOutput = OutputOnFailure
+	var events eventLog
+	r := NewJUnitReporter("golist")
+	l.AddObserver(&events).AddObserver(r)
+	l.AddTask(ct)
+	if err := l.RunAndWait(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The events and states are about the CommandTask,
+	// rather than the Task it embeds
+	for _, e := range events {
+		if e.Task != nil && e.Task != TaskRunner(ct) {
+			t.Errorf("expected the %s event to be about the CommandTask, got %T", e.Type, e.Task)
+		}
+	}
+	if s := ct.GetTaskStates()[0]; s.runner != TaskRunner(ct) {
+		t.Errorf("expected the state to describe the CommandTask, got %T", s.runner)
+	}
+
+	// So the JUnit report finds its output
+	var b strings.Builder
+	if err := r.Write(&b, l); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "<system-out>hello</system-out>") {
+		t.Errorf("expected the command's output in the report, got %q", b.String())
+	}
+}
@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package golist
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// killProcessGroup kills the command's process
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,80 @@
+package golist
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is the error stored for a Task whose Action
+// panicked. It holds the value passed to `panic` and the
+// stack trace from where the panic happened.
+type PanicError struct {
+	Value interface{} // The value passed to `panic`
+	Stack []byte      // The stack trace of the panicking goroutine
+}
+
+// Error returns the panic value formatted as an error message
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it's an error,
+// otherwise it returns nil
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// TaskError is an error from a task in a List, along with
+// the task's path in the tree of tasks and its final status.
+// They're returned in tree order by `List.Failures` and
+// `TaskGroup.Failures`.
+type TaskError struct {
+	Path   []string   // The names of the task's ancestors, followed by its own (a task's name is its ID, or its message if it has no ID)
+	Status TaskStatus // The task's status
+	Err    error      // The error from the task
+}
+
+// Error returns the task's path followed by its error
+// (e.g. "deploy › services › api: exit status 1")
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("%s: %v", fmtPath(e.Path), e.Err)
+}
+
+// Unwrap returns the error from the task
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// failures returns a TaskError for each of the nodes
+// with an error, in tree order
+func failures(nodes []taskNode) []*TaskError {
+	var errs []*TaskError
+	for _, n := range nodes {
+		if n.err != nil {
+			errs = append(errs, &TaskError{
+				Path:   n.path,
+				Status: n.state.Status,
+				Err:    n.err,
+			})
+		}
+	}
+	return errs
+}
+
+// recoverPanic wraps the action function `f` so that
+// if it panics, the panic is recovered and returned
+// as a *PanicError.
+func recoverPanic(f func(TaskContext) error) func(TaskContext) error {
+	return func(c TaskContext) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{
+					Value: r,
+					Stack: debug.Stack(),
+				}
+			}
+		}()
+		return f(c)
+	}
+}
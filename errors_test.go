@@ -0,0 +1,60 @@
+package golist
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPanicError(t *testing.T) {
+	e := &PanicError{Value: "oh no"}
+	if m := e.Error(); m != "panic: oh no" {
+		t.Errorf("expected message %q, got %q", "panic: oh no", m)
+	}
+	if e.Unwrap() != nil {
+		t.Error("expected a non-error panic value to unwrap to nil")
+	}
+
+	inner := errors.New("inner")
+	e = &PanicError{Value: inner}
+	if !errors.Is(e, inner) {
+		t.Error("expected an error panic value to be unwrapped")
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	f := recoverPanic(func(c TaskContext) error {
+		panic("oh no")
+	})
+	err := f(&taskContext{})
+
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a *PanicError, got %T", err)
+	}
+	if pe.Value != "oh no" {
+		t.Errorf("expected panic value %q, got %v", "oh no", pe.Value)
+	}
+	if !strings.Contains(string(pe.Stack), "TestRecoverPanic") {
+		t.Error("expected the stack trace to include the panicking function")
+	}
+}
+
+func TestTaskError(t *testing.T) {
+	inner := errors.New("exit status 1")
+	var err error = &TaskError{
+		Path:   []string{"deploy", "services", "api"},
+		Status: TaskFailed,
+		Err:    &PanicError{Value: inner},
+	}
+	if m, e := err.Error(), "deploy › services › api: panic: exit status 1"; m != e {
+		t.Errorf("expected message %q, got %q", e, m)
+	}
+	if !errors.Is(err, inner) {
+		t.Error("expected errors.Is to find the underlying error")
+	}
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Error("expected errors.As to find the *PanicError")
+	}
+}
@@ -2,6 +2,15 @@ module github.com/a-poor/golist
 
 go 1.17
 
-require github.com/hashicorp/go-multierror v1.1.1
+require (
+	github.com/hashicorp/go-multierror v1.1.1
+	github.com/mattn/go-runewidth v0.0.15
+	golang.org/x/sys v0.13.0
+	golang.org/x/term v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
-require github.com/hashicorp/errwrap v1.0.0 // indirect
+require (
+	github.com/hashicorp/errwrap v1.0.0 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+)
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/mattn/go-runewidth"
 )
 
 // IndentSize is the number of spaces to indent each line
@@ -30,6 +33,20 @@ var (
 
 	// ErrNilAction is returned when no action is set for a task
 	ErrNilAction = errors.New("nil action")
+
+	// ErrTimedOut is returned when a task or task group
+	// doesn't finish before its `Timeout` passes
+	ErrTimedOut = errors.New("timed out")
+
+	// ErrDuplicateID is returned when two sibling tasks have the same ID
+	ErrDuplicateID = errors.New("duplicate task id")
+
+	// ErrUnknownDependency is returned when a task depends on
+	// an ID that doesn't belong to one of its siblings
+	ErrUnknownDependency = errors.New("unknown dependency")
+
+	// ErrDependencyCycle is returned when tasks depend on each other in a cycle
+	ErrDependencyCycle = errors.New("dependency cycle")
 )
 
 // TaskStatus represents the current status of a task
@@ -41,6 +58,8 @@ const (
 	TaskCompleted                    // TaskCompleted is the status for a task that has completed successfully
 	TaskFailed                       // TaskFailed is the status for a task that returned a non-`nil` error
 	TaskSkipped                      // TaskSkipped is the status for a task that was skipped (either manually or from a previous task's error)
+	TaskTimedOut                     // TaskTimedOut is the status for a task that didn't finish before its timeout passed
+	TaskCancelled                    // TaskCancelled is the status for a task that was interrupted because its context was cancelled
 )
 
 // Format a TaskStatus as a string
@@ -56,6 +75,10 @@ func (s TaskStatus) String() string {
 		return "Failed"
 	case TaskSkipped:
 		return "Skipped"
+	case TaskTimedOut:
+		return "Timed Out"
+	case TaskCancelled:
+		return "Cancelled"
 	default:
 		return "Unknown"
 	}
@@ -73,20 +96,51 @@ func (s TaskStatus) String() string {
 //
 // Typically, you'll at least want to set `Writer`,
 // `Delay`, and `StatusIndicator`.
+//
+// If `Interactive` is true and stdin is a terminal, the run
+// can be controlled from the keyboard while the list is drawn
+// in place: "j" and "k" (or the arrow keys) select a task, "e"
+// expands or collapses the selected task group, "l" shows or
+// hides the lines the selected task has printed, "p" pauses
+// or resumes starting new tasks (see `Pause`), and "q" (or
+// Ctrl-C) cancels the run.
 type List struct {
 	Writer          io.Writer        // Writer to use for printing output
 	Delay           time.Duration    // Delay between prints
 	StatusIndicator StatusIndicators // Map of statuses to status indicators
 	Tasks           []TaskRunner     // List of tasks to run
-	FailOnError     bool             // If true, the task execution stops on the first error. Note: this will be ignored if Concurrent is true.
-	MaxLineLength   int              // Maximum line length for printing (0 = no limit)
+	FailOnError     bool             // If true, the task execution stops on the first error. If Concurrent is true, the first error also cancels the running tasks.
+	MaxLineLength   int              // Maximum line length for printing, in terminal cells (0 = no limit, AutoLineLength = the terminal's width)
+	Overflow        OverflowMode     // What to do with lines longer than MaxLineLength (truncate or wrap them)
+	MaxHeight       int              // Maximum number of lines to draw, collapsing finished tasks to fit (0 = the terminal's height, if known)
 	ClearOnComplete bool             // If true, the list will clear the list after it finishes running
-	Concurrent      bool             // Should the tasks be run concurrently? Note: If true, ignores the FailOnError flag
-
-	printDone chan bool          // Is the printing loop done
-	running   bool               // Is the list running?
-	cancel    context.CancelFunc // A context cancel function for stopping the list run
-	printQ    chan string        // A channel for printing to the terminal while displaying the list
+	Concurrent      bool             // Should the tasks be run concurrently? Ignored if tasks have dependencies.
+	MaxConcurrency  int              // Maximum number of tasks to run at once when running concurrently (0 = no limit)
+	ShowSummary     bool             // If true, a summary of the run (see `Summary`) is printed when RunAndWait finishes
+	SummarySlowest  int              // The number of slowest tasks listed in the summary (0 or negative = none)
+	ShowElapsed     bool             // If true, a timer is shown next to running tasks and the duration next to finished ones
+	Observers       []Observer       // Observers to notify of events during the run (see `AddObserver`)
+	Display         DisplayMode      // How to draw the list. By default, it's redrawn in place if Writer is a terminal, otherwise status changes are logged line by line
+	Interactive     bool             // If true and stdin is a terminal, the list can be controlled with the keyboard while it's redrawn in place (see above)
+	HandleSignals   bool             // If true, RunAndWait cancels the run when the process gets SIGINT or SIGTERM, and exits on a second one
+	TaskOutput      TaskOutputMode   // What to do with the lines that tasks print. By default, they're printed above the list straight away
+	TailLines       int              // The number of lines shown below each running task, if TaskOutput is OutputTail
+	OutputBuffer    int              // The number of printed lines each task keeps, dropping the oldest ones (0 = DefaultOutputBufferLines, negative = no limit)
+
+	queued      int32              // Number of tasks waiting for a free slot to run (accessed atomically)
+	pause       gate               // Holds tasks back from starting while the list is paused
+	observeMu   sync.Mutex         // Guards the observers and the fields below
+	display     Observer           // The renderer, if it draws the list from its events
+	events      []Event            // Events waiting to be sent to the observers
+	dispatching bool               // Is a goroutine sending the events to the observers?
+	dispatched  *sync.Cond         // Signalled when the events have all been sent
+	mu          sync.Mutex         // Guards the fields below, which are shared with the print loop
+	printDone   chan bool          // Closed when the printing loop is done
+	running     bool               // Is the list running?
+	cancel      context.CancelFunc // A context cancel function for stopping the list run
+	stopRun     context.CancelFunc // Cancels the tasks' context, while the tasks are running
+	inPlace     bool               // Is the list being redrawn in place, so tasks' output can be shown below them?
+	printQ      chan string        // A channel for printing to the terminal while displaying the list
 }
 
 // NewList creates a new task list with some sensible defaults.
@@ -96,6 +150,10 @@ func NewList() *List {
 		Writer:          os.Stdout,
 		Delay:           DefaultListDelay,
 		StatusIndicator: CreateDefaultStatusIndicator(),
+		MaxLineLength:   AutoLineLength,
+		SummarySlowest:  DefaultSummarySlowest,
+		TailLines:       DefaultTailLines,
+		OutputBuffer:    DefaultOutputBufferLines,
 	}
 }
 
@@ -106,6 +164,10 @@ func NewListWithWriter(w io.Writer) *List {
 		Writer:          w,
 		Delay:           DefaultListDelay,
 		StatusIndicator: CreateDefaultStatusIndicator(),
+		MaxLineLength:   AutoLineLength,
+		SummarySlowest:  DefaultSummarySlowest,
+		TailLines:       DefaultTailLines,
+		OutputBuffer:    DefaultOutputBufferLines,
 	}
 }
 
@@ -119,19 +181,99 @@ func (l *List) AddTask(t TaskRunner) *List {
 	return l
 }
 
+// AddObserver adds an Observer to be notified of the events
+// during the List's run (like tasks starting and finishing).
+// Observers should be added before the List starts.
+func (l *List) AddObserver(o Observer) *List {
+	l.observeMu.Lock()
+	defer l.observeMu.Unlock()
+	l.Observers = append(l.Observers, o)
+	return l
+}
+
+// emit sends the event `e` to the List's observers.
+//
+// The events are sent one at a time, in order, but without holding
+// the lock, so observers can print lines (which emits events of its
+// own). If an event is already being sent, `e` is queued to be sent
+// by the goroutine sending it, once the observers have returned.
+func (l *List) emit(e Event) {
+	l.observeMu.Lock()
+	l.events = append(l.events, e)
+	if l.dispatching {
+		l.observeMu.Unlock()
+		return
+	}
+	l.dispatching = true
+	for len(l.events) > 0 {
+		e := l.events[0]
+		l.events = l.events[1:]
+		observers, display := l.Observers, l.display
+		l.observeMu.Unlock()
+
+		for _, o := range observers {
+			o.OnEvent(e)
+		}
+		if display != nil {
+			display.OnEvent(e)
+		}
+		l.observeMu.Lock()
+	}
+	l.dispatching = false
+	l.idle().Broadcast()
+	l.observeMu.Unlock()
+}
+
+// flush waits for the queued events to be sent to the observers
+func (l *List) flush() {
+	l.observeMu.Lock()
+	defer l.observeMu.Unlock()
+	for l.dispatching {
+		l.idle().Wait()
+	}
+}
+
+// idle returns the condition that's signalled once the events have
+// all been sent to the observers. The observeMu lock must be held.
+func (l *List) idle() *sync.Cond {
+	if l.dispatched == nil {
+		l.dispatched = sync.NewCond(&l.observeMu)
+	}
+	return l.dispatched
+}
+
 // Start begins displaying the list statuses
 // from a background goroutine.
 //
 // Note: If the list is created without a writer,
 // it will be set to `os.Stdout`.
 func (l *List) Start() {
+	r := l.start()
+	if r == nil {
+		return
+	}
+
+	// Some renderers draw the list from its events
+	l.observeMu.Lock()
+	l.display, _ = r.(Observer)
+	l.observeMu.Unlock()
+
+	l.emit(Event{Type: EventListStarted, Time: time.Now()})
+}
+
+// start starts the display loop, as described in `Start`, and
+// returns its renderer (or nil if the list was already running).
+func (l *List) start() renderer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if l.Writer == nil {
 		l.Writer = os.Stdout
 	}
 
 	// Check if it's already displaying
 	if l.running {
-		return
+		return nil
 	}
 
 	// Create a cancelable context
@@ -139,41 +281,61 @@ func (l *List) Start() {
 	l.cancel = cancel
 
 	// Create the channel for printing
-	l.printQ = make(chan string)
-
-	// Create a channel to tel the Stop function when the
-	// print loop has completed
-	l.printDone = make(chan bool)
-	donePrinting := func() {
-		l.printDone <- true
+	printQ := make(chan string)
+	l.printQ = printQ
+
+	// Create a channel to tell the Stop function (and any
+	// calls to Println) when the print loop has completed
+	printDone := make(chan bool)
+	l.printDone = printDone
+
+	// Create the renderer for drawing the list
+	r := l.newRenderer()
+	_, l.inPlace = r.(*terminalRenderer)
+
+	// Listen for the terminal being resized
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+
+	// Read key presses, if the list is interactive
+	var kb *keyboard
+	var keys <-chan key
+	var ctl *controls
+	if tr, ok := r.(*terminalRenderer); ok && l.Interactive && isTerminal(stdin) {
+		if k, err := openKeyboard(stdin); err == nil {
+			kb, keys, ctl = k, k.keys, newControls(l)
+			tr.controls, tr.raw = ctl, true
+		}
 	}
 
 	// Start the display loop
 	go func() {
-		defer donePrinting() // Tell the Stop function that we're done printing
-		ts := l.getTaskStates()
-		l.print(ts)
+		defer close(printDone) // Tell the Stop function that we're done printing
+		defer signal.Stop(resized)
+		defer kb.close() // Restore the terminal, if it was put in raw mode
+		r.render(l.getTaskStates())
 		for {
 			select {
 			case <-ctx.Done(): // Check if the print loop should stop
 
-				// Perform a final clear and an optional print
+				// Perform a final clear or print
 				// depending on `ClearOnComplete`
-				ts := l.getTaskStates()
-				if l.ClearOnComplete {
-					l.clear(ts)
-					return
-				}
-
-				l.clearThenPrint(ts)
+				r.finish(l.getTaskStates(), l.ClearOnComplete)
 				return
 
-			case s := <-l.printQ: // Check if there's a message to print
-				fmt.Fprintln(l.Writer, s)
+			case s := <-printQ: // Check if there's a message to print
+				r.println(s)
+
+			case <-resized: // Check if the terminal was resized
+				r.resize()
+				r.render(l.getTaskStates())
+
+			case k := <-keys: // Check if a key was pressed
+				ctl.press(k)
+				r.render(l.getTaskStates())
 
 			default: // Otherwise, print the list
-				ts := l.getTaskStates()
-				l.clearThenPrint(ts)
+				r.render(l.getTaskStates())
 				l.StatusIndicator.Next()
 				time.Sleep(l.Delay)
 			}
@@ -182,31 +344,60 @@ func (l *List) Start() {
 
 	// Set the running flag
 	l.running = true
+	return r
 }
 
 // createRootContext creates a base TaskContext to be passed
-// down to the subtasks to create sub-contexts.
+// down to the subtasks to create sub-contexts. The context.Context
+// `ctx` is shared by all subtasks, so cancelling it will reach
+// every nested TaskGroup and Task.
 //
 // Note: The SetMessage function is a no-op, since the
 // top-level list doesn't have a message to set.
-func (l *List) createRootContext() TaskContext {
+func (l *List) createRootContext(ctx context.Context) TaskContext {
+	// Output can only be shown below the tasks if the
+	// list is redrawn in place, otherwise it's printed
+	l.mu.Lock()
+	mode := l.TaskOutput
+	if mode == OutputTail && !l.inPlace {
+		mode = OutputPassthrough
+	}
+	l.mu.Unlock()
+
 	return &taskContext{
-		setMessage: func(m string) {},
+		ctx:         ctx,
+		setMessage:  func(m string) {},
+		setProgress: func(current, total int64) {},
 		println: func(a ...interface{}) error {
-			return l.Println(a...)
+			return l.enqueuePrint(fmt.Sprint(a...))
 		},
 		printfln: func(f string, a ...interface{}) error {
-			return l.Printfln(f, a...)
+			return l.enqueuePrint(fmt.Sprintf(f, a...))
 		},
+		observe:     l.observer(),
+		gate:        &l.pause,
+		outputMode:  mode,
+		outputLines: l.OutputBuffer,
 	}
 }
 
+// observer returns the function for sending events
+// to the List's observers, or nil if it has none
+func (l *List) observer() func(Event) {
+	l.observeMu.Lock()
+	defer l.observeMu.Unlock()
+	if len(l.Observers) == 0 && l.display == nil {
+		return nil
+	}
+	return l.emit
+}
+
 // runSync runs the TaskRunners in this TaskGroup synchronously
 func (l *List) runSync(c TaskContext) error {
 	var skipRemaining bool
 	for _, t := range l.Tasks {
-		if skipRemaining {
-			t.SetStatus(TaskSkipped)
+		if skipRemaining || c.Context().Err() != nil {
+			skipTask(c, t)
 			continue
 		}
 		err := t.Run(c)
@@ -218,41 +409,125 @@ func (l *List) runSync(c TaskContext) error {
 }
 
 // runAsync runs the TaskRunners in this TaskGroup concurrently
-// and blocks until they are all done.
+// (at most `MaxConcurrency` at a time) and blocks until they
+// are all done.
+//
+// If `FailOnError` is true, the first error cancels the context
+// shared by the tasks, so tasks that are still running are
+// cancelled and tasks that haven't started are skipped.
 func (l *List) runAsync(c TaskContext) error {
+	c, cancel := withCancel(c)
+	defer cancel()
+
+	p := newWorkerPool(l.MaxConcurrency, &l.queued)
+	defer p.close()
+
 	var wg sync.WaitGroup
 	for _, t := range l.Tasks {
 		wg.Add(1)
-		go func(t TaskRunner, c TaskContext) {
+		t := t
+		p.submit(func() {
 			defer wg.Done()
-			t.Run(c)
-		}(t, c)
+			if err := t.Run(c); err != nil && l.FailOnError {
+				cancel()
+			}
+		})
 	}
 	wg.Wait()
 	time.Sleep(l.Delay)
 	return l.GetError()
 }
 
+// runGraph runs the TaskRunners in this List according to
+// their dependencies and blocks until they are all done.
+func (l *List) runGraph(c TaskContext) error {
+	p := newWorkerPool(l.MaxConcurrency, &l.queued)
+	defer p.close()
+	runGraph(c, l.Tasks, l.FailOnError, p)
+	return l.GetError()
+}
+
+// interrupt cancels the tasks' context, if they're running
+func (l *List) interrupt() {
+	l.mu.Lock()
+	stopRun := l.stopRun
+	l.mu.Unlock()
+	if stopRun != nil {
+		stopRun()
+	}
+}
+
+// Validate checks that the dependencies between the List's tasks
+// (and between the tasks in any nested TaskGroups) are valid.
+//
+// It returns an error wrapping ErrDuplicateID, ErrUnknownDependency,
+// or ErrDependencyCycle if they aren't.
+func (l *List) Validate() error {
+	return validateGraph(l.Tasks)
+}
+
 // Run starts running the tasks in the `List`
 // and if `FailOnError` is set to true, returns
 // an error if any of the tasks fail.
+//
+// Run is equivalent to calling `RunContext` with
+// `context.Background()`.
 func (l *List) Run() error {
+	return l.RunContext(context.Background())
+}
+
+// RunContext starts running the tasks in the `List`, like `Run`,
+// using `ctx` as the parent of every task's context.
+//
+// When `ctx` is cancelled, the cancellation is passed down to
+// all running tasks (through `TaskContext.Context`) and any tasks
+// that haven't started yet are skipped. If the run is cancelled
+// and no task returned an error, the context's error is returned.
+//
+// If any tasks declare dependencies (see `Dependent`), the tasks
+// are validated before anything runs and each one is started as
+// soon as its dependencies have finished, regardless of `Concurrent`.
+func (l *List) RunContext(ctx context.Context) error {
+	// Check the task dependencies before running anything
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
 	// Starts the list if it hasn't already started
 	l.Start()
 
+	// Let the run be cancelled from the keyboard
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	l.mu.Lock()
+	l.stopRun = cancel
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.stopRun = nil
+		l.mu.Unlock()
+	}()
+
 	// Create a "base context" to be passed down
 	// for subtasks to create TaskContexts
-	rootTaskCtx := l.createRootContext()
+	rootTaskCtx := l.createRootContext(ctx)
 
-	// Check if running concurrently...
+	// Check if running by dependencies or concurrently...
 	var err error
-	if l.Concurrent {
+	if hasDependencies(l.Tasks) {
+		err = l.runGraph(rootTaskCtx)
+	} else if l.Concurrent {
 		err = l.runAsync(rootTaskCtx)
 	} else {
 		// Otherwise, run synchronously
 		err = l.runSync(rootTaskCtx)
 	}
 
+	// Report the cancellation if nothing else went wrong
+	if err == nil {
+		err = ctx.Err()
+	}
+
 	// Return the error
 	return err
 }
@@ -263,26 +538,41 @@ func (l *List) Run() error {
 // Stop also clears and prints one final time
 // before finishing.
 func (l *List) Stop() {
+	l.mu.Lock()
+
 	// Check if it's already NOT displaying
 	if !l.running {
+		l.mu.Unlock()
 		return
 	}
+	cancel, printDone := l.cancel, l.printDone
+	l.mu.Unlock()
 
 	// Send the cancel signal
-	if l.cancel != nil {
-		l.cancel()
+	if cancel != nil {
+		cancel()
 	}
 
 	// Wait for the print loop to finish
-	<-l.printDone
-
-	if l.printQ != nil {
-		close(l.printQ)
+	<-printDone
+
+	// Reset the list, unless it was restarted while waiting
+	l.mu.Lock()
+	stopped := l.printDone == printDone
+	if stopped {
+		l.running = false
+		l.cancel = nil
+		l.printQ = nil
+	}
+	l.mu.Unlock()
+
+	l.emit(Event{Type: EventListStopped, Time: time.Now()})
+	l.flush()
+	if stopped {
+		l.observeMu.Lock()
+		l.display = nil
+		l.observeMu.Unlock()
 	}
-
-	l.running = false
-	l.cancel = nil
-	l.printQ = nil
 }
 
 // RunAndWait starts to display the task list statuses,
@@ -290,109 +580,197 @@ func (l *List) Stop() {
 // before returning.
 //
 // RunAndWait is a convenience function that combines
-// `Start`, `Run`, and `Stop`.
+// `Start`, `Run`, and `Stop`. If `ShowSummary` is true,
+// a summary of the run is printed below the list (see
+// `printSummary`).
+//
+// If `HandleSignals` is true, the first SIGINT (e.g. from
+// Ctrl-C) or SIGTERM cancels the run, like cancelling its
+// context, and the final state of the list is drawn as
+// usual. If a second signal arrives before the tasks have
+// finished, the list is stopped (drawing its final state
+// and restoring the terminal) and the process exits with
+// the signal's conventional exit code (e.g. 130 for SIGINT).
 func (l *List) RunAndWait() error {
+	return l.RunAndWaitContext(context.Background())
+}
+
+// RunAndWaitContext is like `RunAndWait` but runs the
+// tasks with `RunContext`, using `ctx` as the parent
+// context for all of the tasks.
+func (l *List) RunAndWaitContext(ctx context.Context) error {
+	if l.HandleSignals {
+		var stop func()
+		ctx, stop = l.notifySignals(ctx)
+		defer stop()
+	}
 	l.Start()
-	err := l.Run()
+	err := l.RunContext(ctx)
 	l.Stop()
+	if l.ShowSummary && l.Writer != nil {
+		l.printSummary()
+	}
 	return err
 }
 
+// printSummary writes the summary of the run to the List's
+// writer in a way that fits its display, so machine-readable
+// output stays valid: as a line of JSON with DisplayJSON, as
+// comments with DisplayTAP, and as plain text otherwise.
+func (l *List) printSummary() {
+	switch l.Display {
+	case DisplayJSON:
+		writeJSONSummary(l.Writer, l.Summary())
+	case DisplayTAP:
+		writeTAPComments(l.Writer, l.Summary())
+	default:
+		fmt.Fprintf(l.Writer, "\n%s\n", l.Summary())
+	}
+}
+
 // getTaskStates returns a slice of TaskStates
-// for all child tasks
+// for all child tasks, followed by a row with the
+// number of queued tasks if any are waiting to run.
 func (l *List) getTaskStates() []*TaskState {
 	var messages []*TaskState
 	for _, t := range l.Tasks {
 		msgs := t.GetTaskStates()
 		messages = append(messages, msgs...)
 	}
+	if n := atomic.LoadInt32(&l.queued); n > 0 {
+		messages = append(messages, &TaskState{
+			Message: fmt.Sprintf("%d queued", n),
+			Status:  TaskNotStarted,
+		})
+	}
 	return messages
 }
 
 // truncateMessage will truncate the message to if it's too long
-// based on the size parameter.
+// based on the size parameter. The size is measured in terminal
+// cells, so wide characters (like CJK characters) count twice.
 //
 // If the message is truncated, all trailing spaces will be removed
 // and an ellipsis ("…") is added to the end. An extra character
 // will be removed to fit the elipsis, if necessary. If the size
 //  is 0, an ellipsis character is still returned.
 func (l *List) truncateMessage(m string, size int) string {
-	rm := []rune(m)
-	if len(rm) <= size { // No truncation needed
+	if cellWidth(m) <= size { // No truncation needed
 		return m
 	}
 	if size <= 1 { // Truncate everything
 		return "…"
 	}
 
-	// Remove an extra character to fit the ellipsis
+	// Remove an extra cell to fit the ellipsis
 	tsize := size - 1
 
-	return strings.TrimSuffix(string(rm[0:tsize]), " ") + "…"
+	return strings.TrimSuffix(runewidth.Truncate(m, tsize, ""), " ") + "…"
 }
 
-// formatMessage formats a message row for displaying.
-// The format used is: [depth] [status] [message]
-// and it's length is (optionally) limited by the
-// MaxLineLength parameter.
-func (l *List) formatMessage(m *TaskState) string {
-	n := m.Depth * IndentSize
-	d := strings.Repeat(" ", n)
-	i := l.StatusIndicator.Get(m.Status)
-
-	// If no no truncate text, just return the formatted
-	// status message
-	if l.MaxLineLength == 0 {
-		return fmt.Sprintf("%s%s %s", d, i, m.Message)
+// fmtRetry returns a note describing the task's retry
+// attempt (e.g. "(retry 2/5 in 3s)"), or an empty string
+// if the task hasn't been retried.
+func (l *List) fmtRetry(m *TaskState) string {
+	if m.Attempt < 2 {
+		return ""
+	}
+	if m.RetryAt.IsZero() {
+		return fmt.Sprintf("(retry %d/%d)", m.Attempt, m.MaxAttempts)
 	}
 
-	// Otherwise, truncate the result
-	size := l.MaxLineLength - (n + 1)
-	return fmt.Sprintf("%s%s %s", d, i, l.truncateMessage(m.Message, size))
+	// Round the time left up to the nearest second
+	wait := time.Until(m.RetryAt)
+	if wait < 0 {
+		wait = 0
+	}
+	secs := (wait + time.Second - 1) / time.Second
+	return fmt.Sprintf("(retry %d/%d in %ds)", m.Attempt, m.MaxAttempts, secs)
 }
 
-// fmtPrint returns the formatted list of messages
-// and statuses, using the supplied TaskStates
-func (l *List) fmtPrint(ts []*TaskState) string {
-	s := make([]string, 0)
-	for _, t := range ts {
-		s = append(s, l.formatMessage(t))
+// fmtElapsed returns a live timer for a running task (e.g. "(12s)")
+// or the duration of a finished one (e.g. "(2.3s)"), if the List's
+// ShowElapsed is true. Otherwise, it returns an empty string.
+func (l *List) fmtElapsed(m *TaskState) string {
+	if !l.ShowElapsed || m.StartTime.IsZero() {
+		return ""
+	}
+	if m.EndTime.IsZero() {
+		// Only count whole seconds while running, so
+		// the line isn't redrawn on every tick
+		return fmt.Sprintf("(%s)", m.Elapsed.Truncate(time.Second))
 	}
-	return strings.Join(s, "\n")
+	return fmt.Sprintf("(%s)", fmtDuration(m.Elapsed))
 }
 
-// print prints the current task states
-func (l *List) print(states []*TaskState) {
-	s := l.fmtPrint(states)
-	fmt.Fprintln(l.Writer, s)
+// formatMessage formats a message row for displaying.
+// The format used is: [depth] [status] [message] [queued] [retry] [progress] [elapsed]
+// and it's length is (optionally) limited by the
+// MaxLineLength parameter. If the message is wrapped,
+// its lines are joined with newlines.
+func (l *List) formatMessage(m *TaskState) string {
+	return strings.Join(l.formatLines(m, l.MaxLineLength), "\n")
 }
 
-// fmtClear returns a string of ANSI escape characters
-// to clear the `n` lines previously printed.
-func (l *List) fmtClear(n int) string {
-	s := "\033[1A" // Move up a line
-	s += "\033[K"  // Clear the line
-	s += "\r"      // Move back to the beginning of the line
-	return strings.Repeat(s, n)
-}
+// formatLines formats a message row for displaying, limiting
+// it to `width` terminal cells (if `width` is greater than 0)
+// by truncating or wrapping it, depending on the List's Overflow.
+func (l *List) formatLines(m *TaskState, width int) []string {
+	d := strings.Repeat(" ", m.Depth*IndentSize)
+	i := l.StatusIndicator.Get(m.Status)
+	if m.logLine {
+		i = "│"
+	}
+	prefix := fmt.Sprintf("%s%s ", d, i)
+
+	msg := m.Message
+	if m.Queued > 0 {
+		msg += fmt.Sprintf(" (%d queued)", m.Queued)
+	}
+	if r := l.fmtRetry(m); r != "" {
+		msg += " " + r
+	}
+	if p := fmtProgress(m); p != "" {
+		msg += " " + p
+	}
+	if e := l.fmtElapsed(m); e != "" {
+		msg += " " + e
+	}
 
-// clear clears the previous task states using
-// ANSII escape characters
-func (l *List) clear(states []*TaskState) {
-	n := len(states)
-	s := l.fmtClear(n)
-	fmt.Fprintln(l.Writer, s)
+	// If the message fits, just return the
+	// formatted status message
+	size := width - cellWidth(prefix)
+	if width <= 0 || cellWidth(msg) <= size {
+		return []string{prefix + msg}
+	}
+
+	// Otherwise, truncate the result...
+	if l.Overflow != OverflowWrap {
+		return []string{prefix + l.truncateMessage(msg, size)}
+	}
+
+	// ...or wrap it, lining up the continuation
+	// lines with the start of the message
+	lines := wrapText(msg, size)
+	indent := strings.Repeat(" ", cellWidth(prefix))
+	for j := range lines {
+		if j == 0 {
+			lines[j] = prefix + lines[j]
+		} else {
+			lines[j] = indent + lines[j]
+		}
+	}
+	return lines
 }
 
-// clearThenPrint is a shorcut that clears the previous
-// task states and prints the current task states.
-//
-// It is equivalent to calling `clear` and `print`
-// except that it only uses one call to `fmt.Fprintln`.
-func (l *List) clearThenPrint(states []*TaskState) {
-	c := l.fmtClear(len(states))
-	s := l.fmtPrint(states)
-	fmt.Fprintln(l.Writer, c+s)
+// fmtDuration formats a duration for displaying, with one
+// decimal place for durations under a minute (e.g. "2.3s")
+// and to the nearest second otherwise (e.g. "1m23s").
+func fmtDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
 }
 
 // Println prints information to the List's Writer (which is
@@ -405,12 +783,7 @@ func (l *List) clearThenPrint(states []*TaskState) {
 // Note: If Println is called while the list is not running,
 // it will return the error ErrListNotRunning.
 func (l *List) Println(a ...interface{}) error {
-	if l.printQ == nil {
-		return ErrNoWriter
-	}
-	s := fmt.Sprint(a...)
-	l.printQ <- s
-	return nil
+	return l.print(fmt.Sprint(a...))
 }
 
 // Printfln prints a formatted string to the list's writer
@@ -423,14 +796,38 @@ func (l *List) Println(a ...interface{}) error {
 // Note: If Printfln is called while the list is not running,
 // it will return the error ErrListNotRunning.
 func (l *List) Printfln(f string, d ...interface{}) error {
-	if l.printQ == nil {
-		return ErrNoWriter
+	return l.print(fmt.Sprintf(f, d...))
+}
+
+// print prints the line `s` (like `Println`) and tells the
+// observers. Lines printed by tasks skip this, since the
+// tasks tell the observers themselves.
+func (l *List) print(s string) error {
+	if err := l.enqueuePrint(s); err != nil {
+		return err
 	}
-	s := fmt.Sprintf(f, d...)
-	l.printQ <- s
+	l.emit(Event{Type: EventPrintln, Time: time.Now(), Line: s})
 	return nil
 }
 
+// enqueuePrint passes the string `s` to the display goroutine
+// to be printed. It's safe to call from multiple goroutines.
+func (l *List) enqueuePrint(s string) error {
+	l.mu.Lock()
+	printQ, printDone := l.printQ, l.printDone
+	l.mu.Unlock()
+
+	if printQ == nil {
+		return ErrNoWriter
+	}
+	select {
+	case printQ <- s:
+		return nil
+	case <-printDone:
+		return ErrListNotRunning
+	}
+}
+
 // GetError returns the errors from the child tasks
 func (l *List) GetError() error {
 	var err *multierror.Error
@@ -439,3 +836,11 @@ func (l *List) GetError() error {
 	}
 	return err.ErrorOrNil()
 }
+
+// Failures returns a TaskError for each task in the List
+// (including tasks in TaskGroups) that has an error, in
+// tree order. Unlike `GetError`, each error records the
+// path of the task it came from.
+func (l *List) Failures() []*TaskError {
+	return failures(walkTasks(l.Tasks, nil))
+}
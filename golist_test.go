@@ -2,8 +2,11 @@ package golist
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -92,6 +95,12 @@ func TestStatus(t *testing.T) {
 	if s := TaskSkipped.String(); s != "Skipped" {
 		t.Errorf("TaskSkipped.String = %q", s)
 	}
+	if s := TaskTimedOut.String(); s != "Timed Out" {
+		t.Errorf("TaskTimedOut.String = %q", s)
+	}
+	if s := TaskCancelled.String(); s != "Cancelled" {
+		t.Errorf("TaskCancelled.String = %q", s)
+	}
 
 	other := TaskStatus(999)
 	e := "Unknown"
@@ -103,6 +112,7 @@ func TestStatus(t *testing.T) {
 func TestList_NoWriter(t *testing.T) {
 	l := List{}
 	l.Start()
+	defer l.Stop()
 	if l.Writer == nil {
 		t.Error("list's writer should have auto-set")
 	} else if l.Writer != os.Stdout {
@@ -123,15 +133,15 @@ func TestList_runAsync(t *testing.T) {
 	l.Concurrent = true
 	l.Writer = &bytes.Buffer{}
 
-	var t0Start bool
-	var t0Stop bool
+	var t0Start int32
+	var t0Stop int32
 
 	l.AddTask(&Task{
 		Message: "t0",
 		Action: func(c TaskContext) error {
-			t0Start = true
+			atomic.StoreInt32(&t0Start, 1)
 			time.Sleep(time.Millisecond * 100)
-			t0Stop = true
+			atomic.StoreInt32(&t0Stop, 1)
 			return nil
 		},
 	})
@@ -140,10 +150,10 @@ func TestList_runAsync(t *testing.T) {
 		Message: "t1",
 		Action: func(c TaskContext) error {
 			time.Sleep(time.Millisecond * 10)
-			if !t0Start {
+			if atomic.LoadInt32(&t0Start) == 0 {
 				t.Error("t0 should have started already")
 			}
-			if t0Stop {
+			if atomic.LoadInt32(&t0Stop) == 1 {
 				t.Error("t0 shouldn't have finished yet")
 			}
 			return nil
@@ -157,7 +167,7 @@ func TestList_runAsync(t *testing.T) {
 func TestList_createRootContext(t *testing.T) {
 	l := NewList()
 	l.Writer = &bytes.Buffer{}
-	c := l.createRootContext()
+	c := l.createRootContext(context.Background())
 	c.SetMessage("")
 	c.Println("")
 	c.Printfln("")
@@ -211,7 +221,7 @@ func TestListSkipRemaining(t *testing.T) {
 		return nil
 	}))
 	l.Start()
-	l.runSync(l.createRootContext())
+	l.runSync(l.createRootContext(context.Background()))
 
 	if !t0Ran {
 		t.Error("t0 should have run")
@@ -265,3 +275,297 @@ func TestListTruncate2(t *testing.T) {
 	}))
 	l.RunAndWait()
 }
+
+func TestList_RunContextCancel(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var t1Ran bool
+	l.AddTask(NewTask("t0", func(c TaskContext) error {
+		cancel()
+		<-c.Context().Done()
+		return c.Context().Err()
+	}))
+	l.AddTask(NewTaskGroup("g0", []TaskRunner{
+		NewTask("t1", func(c TaskContext) error {
+			t1Ran = true
+			return nil
+		}),
+	}))
+
+	err := l.RunAndWaitContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %q, got %q", context.Canceled, err)
+	}
+	if t1Ran {
+		t.Error("t1 should have been skipped after the context was cancelled")
+	}
+	if s := l.Tasks[1].GetStatus(); s != TaskSkipped {
+		t.Errorf("expected g0 to be %q, got %q", TaskSkipped, s)
+	}
+}
+
+func TestList_RunContextConcurrent(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.Concurrent = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		l.AddTask(NewTaskGroup("group", []TaskRunner{
+			NewTask("task", func(c TaskContext) error {
+				<-c.Context().Done()
+				return c.Context().Err()
+			}),
+		}))
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+	if err := l.RunAndWaitContext(ctx); err == nil {
+		t.Error("expected an error after cancelling the run")
+	}
+}
+
+func TestList_fmtRetry(t *testing.T) {
+	l := NewList()
+
+	s := &TaskState{Message: "test", Attempt: 1, MaxAttempts: 5}
+	if r := l.fmtRetry(s); r != "" {
+		t.Errorf("expected no retry note on the first attempt, got %q", r)
+	}
+
+	s = &TaskState{Message: "test", Attempt: 2, MaxAttempts: 5}
+	if r, e := l.fmtRetry(s), "(retry 2/5)"; r != e {
+		t.Errorf("expected retry note %q, got %q", e, r)
+	}
+
+	s = &TaskState{Message: "test", Attempt: 2, MaxAttempts: 5, RetryAt: time.Now().Add(time.Millisecond * 2500)}
+	if r, e := l.fmtRetry(s), "(retry 2/5 in 3s)"; r != e {
+		t.Errorf("expected retry note %q, got %q", e, r)
+	}
+}
+
+func TestList_MaxConcurrency(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Concurrent = true
+	l.MaxConcurrency = 2
+
+	var running, maxRunning int32
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		l.AddTask(NewTask("task", func(c TaskContext) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return nil
+		}))
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- l.RunAndWait()
+	}()
+
+	// Wait for the queue to fill up, then check the display
+	for atomic.LoadInt32(&l.queued) != 3 {
+		time.Sleep(time.Millisecond)
+	}
+	ts := l.getTaskStates()
+	if n := len(ts); n != 6 {
+		t.Fatalf("expected 5 tasks and a queued row, got %d rows", n)
+	}
+	if m, e := ts[5].Message, "3 queued"; m != e {
+		t.Errorf("expected queued row %q, got %q", e, m)
+	}
+	var notStarted int
+	for _, s := range ts[:5] {
+		if s.Status == TaskNotStarted {
+			notStarted++
+		}
+	}
+	if notStarted != 3 {
+		t.Errorf("expected 3 tasks to not be started, got %d", notStarted)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if m := atomic.LoadInt32(&maxRunning); m > 2 {
+		t.Errorf("expected at most 2 tasks running at once, got %d", m)
+	}
+	if n := len(l.getTaskStates()); n != 5 {
+		t.Errorf("expected no queued row after running, got %d rows", n)
+	}
+}
+
+func TestList_ConcurrentFailOnError(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Concurrent = true
+	l.MaxConcurrency = 2
+	l.FailOnError = true
+
+	started := make(chan struct{})
+	var t2Ran bool
+	l.AddTask(NewTask("t0", func(c TaskContext) error {
+		<-started
+		return errors.New("oh no")
+	}))
+	l.AddTask(NewTask("t1", func(c TaskContext) error {
+		close(started)
+		<-c.Context().Done()
+		return c.Context().Err()
+	}))
+	l.AddTask(NewTask("t2", func(c TaskContext) error {
+		t2Ran = true
+		return nil
+	}))
+
+	if err := l.RunAndWait(); err == nil {
+		t.Error("expected an error")
+	}
+	for i, e := range []TaskStatus{TaskFailed, TaskCancelled, TaskSkipped} {
+		if s := l.Tasks[i].GetStatus(); s != e {
+			t.Errorf("expected t%d to be %q, got %q", i, e, s)
+		}
+	}
+	if t2Ran {
+		t.Error("t2 should have been skipped")
+	}
+}
+
+func TestList_PanicContinues(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+
+	var t1Ran bool
+	l.AddTask(NewTask("t0", func(c TaskContext) error {
+		panic("oh no")
+	}))
+	l.AddTask(NewTask("t1", func(c TaskContext) error {
+		t1Ran = true
+		return nil
+	}))
+
+	err := l.RunAndWait()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Errorf("expected a *PanicError, got %v", err)
+	}
+	if !t1Ran {
+		t.Error("expected t1 to run after t0 panicked")
+	}
+}
+
+func TestFmtDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		time.Millisecond * 2300: "2.3s",
+		time.Second * 12:        "12.0s",
+		time.Second * 83:        "1m23s",
+		time.Minute * 61:        "1h1m0s",
+	}
+	for d, e := range cases {
+		if s := fmtDuration(d); s != e {
+			t.Errorf("expected %s to be formatted as %q, got %q", d, e, s)
+		}
+	}
+}
+
+func TestListTruncateWide(t *testing.T) {
+	l := NewList()
+
+	// Each character takes up two cells
+	m := "日本語のテキスト"
+	e := "日本語…"
+	if n := l.truncateMessage(m, 8); n != e {
+		t.Errorf("expected %q, got %q", e, n)
+	}
+}
+
+func TestList_FormatLines(t *testing.T) {
+	l := NewList()
+	l.StatusIndicator = StatusIndicators{}
+	s := &TaskState{Message: "deploy the services", Depth: 1}
+
+	l.Overflow = OverflowTruncate
+	if lines := l.formatLines(s, 16); len(lines) != 1 || lines[0] != "  – deploy the…" {
+		t.Errorf("expected a truncated line, got %q", lines)
+	}
+
+	l.Overflow = OverflowWrap
+	e := []string{"  – deploy the", "    services"}
+	if lines := l.formatLines(s, 16); strings.Join(lines, "\n") != strings.Join(e, "\n") {
+		t.Errorf("expected %q, got %q", e, lines)
+	}
+
+	if lines := l.formatLines(s, 0); len(lines) != 1 || lines[0] != "  – deploy the services" {
+		t.Errorf("expected an unchanged line, got %q", lines)
+	}
+}
+
+func TestList_FmtElapsed(t *testing.T) {
+	l := NewList()
+	l.StatusIndicator = StatusIndicators{}
+	start := time.Now()
+
+	running := &TaskState{Message: "migrate db", Status: TaskInProgress, StartTime: start, Elapsed: 12600 * time.Millisecond}
+	done := &TaskState{Message: "migrate db", Status: TaskCompleted, StartTime: start, EndTime: start.Add(2300 * time.Millisecond), Elapsed: 2300 * time.Millisecond}
+	notStarted := &TaskState{Message: "migrate db"}
+
+	if m := l.formatMessage(running); m != "– migrate db" {
+		t.Errorf("expected no timer when ShowElapsed is false, got %q", m)
+	}
+
+	l.ShowElapsed = true
+	cases := []struct {
+		s *TaskState
+		e string
+	}{
+		{running, "– migrate db (12s)"},
+		{done, "– migrate db (2.3s)"},
+		{notStarted, "– migrate db"},
+	}
+	for _, c := range cases {
+		if m := l.formatMessage(c.s); m != c.e {
+			t.Errorf("expected %q, got %q", c.e, m)
+		}
+	}
+}
+
+func TestList_Failures(t *testing.T) {
+	e0, e1 := errors.New("e0"), errors.New("e1")
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return e0 }))
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		NewTask("ok", func(c TaskContext) error { return nil }),
+		&Task{ID: "api", Message: "Deploy the API", Action: func(c TaskContext) error { return e1 }},
+	}))
+	l.RunAndWait()
+
+	fs := l.Failures()
+	if len(fs) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(fs))
+	}
+	if p := fmtPath(fs[0].Path); p != "t0" || fs[0].Err != e0 || fs[0].Status != TaskFailed {
+		t.Errorf("unexpected first failure %+v", fs[0])
+	}
+	if p := fmtPath(fs[1].Path); p != "deploy › api" || !errors.Is(fs[1], e1) {
+		t.Errorf("unexpected second failure %+v", fs[1])
+	}
+
+	// GetError is unchanged
+	if err := l.GetError(); !errors.Is(err, e0) || !errors.Is(err, e1) {
+		t.Errorf("expected GetError to include both errors, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package golist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dependent is implemented by TaskRunners that can be
+// referenced by an ID and that can depend on other tasks.
+//
+// Both Task and TaskGroup implement Dependent. Dependencies
+// are resolved between siblings, in the same List or TaskGroup.
+type Dependent interface {
+	GetID() string             // Get the ID other tasks use to depend on this one
+	GetDependencies() []string // Get the IDs of the tasks this one depends on
+}
+
+// getID returns a TaskRunner's ID if it implements
+// Dependent, otherwise it returns an empty string
+func getID(t TaskRunner) string {
+	if d, ok := t.(Dependent); ok {
+		return d.GetID()
+	}
+	return ""
+}
+
+// getDependencies returns a TaskRunner's dependencies
+// if it implements Dependent
+func getDependencies(t TaskRunner) []string {
+	if d, ok := t.(Dependent); ok {
+		return d.GetDependencies()
+	}
+	return nil
+}
+
+// hasDependencies returns true if any of the
+// TaskRunners in `ts` depend on another task
+func hasDependencies(ts []TaskRunner) bool {
+	for _, t := range ts {
+		if len(getDependencies(t)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateGraph checks that the dependencies between the
+// TaskRunners in `ts` are valid: IDs are unique, every
+// dependency refers to a sibling, and there are no cycles.
+//
+// Any TaskRunners that have their own `Validate` method
+// (like TaskGroup) are validated as well.
+func validateGraph(ts []TaskRunner) error {
+	// Index the tasks by ID
+	ids := make(map[string]int)
+	for i, t := range ts {
+		id := getID(t)
+		if id == "" {
+			continue
+		}
+		if _, ok := ids[id]; ok {
+			return fmt.Errorf("%w: %q", ErrDuplicateID, id)
+		}
+		ids[id] = i
+	}
+
+	// Check that all of the dependencies exist
+	for _, t := range ts {
+		for _, d := range getDependencies(t) {
+			if _, ok := ids[d]; !ok {
+				return fmt.Errorf("%w: %q", ErrUnknownDependency, d)
+			}
+		}
+	}
+
+	// Look for cycles with a depth-first search
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(ts))
+	var path []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		state[i] = visiting
+		path = append(path, getID(ts[i]))
+		for _, d := range getDependencies(ts[i]) {
+			j := ids[d]
+			switch state[j] {
+			case visiting:
+				// Find where the cycle starts in the path
+				start := 0
+				for k, id := range path {
+					if id == d {
+						start = k
+					}
+				}
+				cycle := append(append([]string{}, path[start:]...), d)
+				return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " → "))
+			case unvisited:
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range ts {
+		if state[i] == unvisited {
+			if err := visit(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Validate any nested tasks
+	for _, t := range ts {
+		if v, ok := t.(interface{ Validate() error }); ok {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runGraph runs the TaskRunners in `ts` according to their
+// dependencies and blocks until they are all done.
+//
+// Each task is started as soon as all of its dependencies have
+// finished, so independent tasks run concurrently. If one of a
+// task's dependencies fails (or is itself skipped because of a
+// failure), the task is marked as skipped rather than run. If
+// `failOnError` is true, the first failure cancels the context
+// shared by the tasks, no more tasks are started, and the
+// remaining tasks are marked as skipped.
+// Tasks are run with the workerPool `p`, which may limit how
+// many run at once.
+//
+// Note: `ts` is expected to have been checked with `validateGraph`.
+func runGraph(c TaskContext, ts []TaskRunner, failOnError bool, p *workerPool) {
+	type result struct {
+		i   int
+		err error
+	}
+
+	c, cancel := withCancel(c)
+	defer cancel()
+
+	// Build the graph
+	ids := make(map[string]int)
+	for i, t := range ts {
+		if id := getID(t); id != "" {
+			ids[id] = i
+		}
+	}
+	waiting := make([]int, len(ts))      // Number of unfinished dependencies
+	dependents := make([][]int, len(ts)) // Tasks that depend on each task
+	for i, t := range ts {
+		for _, d := range getDependencies(t) {
+			j := ids[d]
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	results := make(chan result)
+	started := make([]bool, len(ts))
+	blocked := make([]bool, len(ts)) // Has a dependency that didn't succeed
+	var running int
+	var stop bool
+
+	start := func(i int) {
+		started[i] = true
+		running++
+		p.submit(func() {
+			err := ts[i].Run(c)
+			results <- result{i, err}
+		})
+	}
+
+	// finish releases the dependents of task `i`, starting any that are
+	// ready. Tasks that can't run are finished in turn, as failures.
+	var finish func(i int, ok bool)
+	finish = func(i int, ok bool) {
+		for _, j := range dependents[i] {
+			waiting[j]--
+			if !ok {
+				blocked[j] = true
+			}
+			if waiting[j] > 0 {
+				continue
+			}
+			if blocked[j] || stop {
+				skipTask(c, ts[j])
+				finish(j, false)
+				continue
+			}
+			start(j)
+		}
+	}
+
+	// Start the tasks without dependencies
+	for i := range ts {
+		if waiting[i] == 0 {
+			start(i)
+		}
+	}
+
+	// Wait for the running tasks to finish
+	for running > 0 {
+		r := <-results
+		running--
+		if r.err != nil && failOnError {
+			stop = true
+			cancel()
+		}
+		finish(r.i, r.err == nil)
+	}
+
+	// Anything left was never started
+	for i, t := range ts {
+		if !started[i] {
+			skipTask(c, t)
+		}
+	}
+}
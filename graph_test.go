@@ -0,0 +1,186 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidateGraph(t *testing.T) {
+	noop := func(c TaskContext) error { return nil }
+
+	ok := []TaskRunner{
+		&Task{ID: "a", Action: noop},
+		&Task{ID: "b", DependsOn: []string{"a"}, Action: noop},
+		&TaskGroup{ID: "c", DependsOn: []string{"a", "b"}},
+		NewTask("no id", noop),
+	}
+	if err := validateGraph(ok); err != nil {
+		t.Errorf("expected no error, got %q", err)
+	}
+
+	dup := []TaskRunner{
+		&Task{ID: "a", Action: noop},
+		&Task{ID: "a", Action: noop},
+	}
+	if err := validateGraph(dup); !errors.Is(err, ErrDuplicateID) {
+		t.Errorf("expected error %q, got %q", ErrDuplicateID, err)
+	}
+
+	unknown := []TaskRunner{
+		&Task{ID: "a", DependsOn: []string{"z"}, Action: noop},
+	}
+	if err := validateGraph(unknown); !errors.Is(err, ErrUnknownDependency) {
+		t.Errorf("expected error %q, got %q", ErrUnknownDependency, err)
+	}
+
+	cycle := []TaskRunner{
+		&Task{ID: "a", DependsOn: []string{"c"}, Action: noop},
+		&Task{ID: "b", DependsOn: []string{"a"}, Action: noop},
+		&Task{ID: "c", DependsOn: []string{"b"}, Action: noop},
+	}
+	err := validateGraph(cycle)
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected error %q, got %q", ErrDependencyCycle, err)
+	}
+	if e := "dependency cycle: a → c → b → a"; err != nil && err.Error() != e {
+		t.Errorf("expected error message %q, got %q", e, err.Error())
+	}
+
+	nested := []TaskRunner{
+		&TaskGroup{ID: "g", Tasks: []TaskRunner{
+			&Task{ID: "a", DependsOn: []string{"a"}, Action: noop},
+		}},
+	}
+	if err := validateGraph(nested); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected nested error %q, got %q", ErrDependencyCycle, err)
+	}
+}
+
+func TestList_RunGraphOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(id string) func(TaskContext) error {
+		return func(c TaskContext) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, id)
+			return nil
+		}
+	}
+
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.AddTask(&Task{ID: "deploy", DependsOn: []string{"build", "test"}, Message: "deploy", Action: record("deploy")})
+	l.AddTask(&Task{ID: "test", DependsOn: []string{"build"}, Message: "test", Action: record("test")})
+	l.AddTask(&Task{ID: "build", Message: "build", Action: record("build")})
+	if err := l.RunAndWait(); err != nil {
+		t.Fatal(err)
+	}
+
+	e := []string{"build", "test", "deploy"}
+	if len(order) != len(e) {
+		t.Fatalf("expected order %v, got %v", e, order)
+	}
+	for i := range e {
+		if order[i] != e[i] {
+			t.Fatalf("expected order %v, got %v", e, order)
+		}
+	}
+}
+
+func TestList_RunGraphParallel(t *testing.T) {
+	// Both tasks depend on "a" and wait for each other,
+	// so they'll only finish if they run concurrently
+	var wg sync.WaitGroup
+	wg.Add(2)
+	both := func(c TaskContext) error {
+		wg.Done()
+		wg.Wait()
+		return nil
+	}
+
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.AddTask(&Task{ID: "a", Message: "a", Action: func(c TaskContext) error { return nil }})
+	l.AddTask(&Task{ID: "b", DependsOn: []string{"a"}, Message: "b", Action: both})
+	l.AddTask(&Task{ID: "c", DependsOn: []string{"a"}, Message: "c", Action: both})
+	if err := l.RunAndWait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestList_RunGraphSkipDependents(t *testing.T) {
+	var ran bool
+	noop := func(c TaskContext) error { return nil }
+
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.AddTask(&Task{ID: "a", Message: "a", Action: func(c TaskContext) error {
+		return errors.New("oh no")
+	}})
+	l.AddTask(&Task{ID: "b", DependsOn: []string{"a"}, Message: "b", Action: noop})
+	l.AddTask(&Task{ID: "c", DependsOn: []string{"b"}, Message: "c", Action: noop})
+	l.AddTask(&Task{ID: "d", Message: "d", Action: func(c TaskContext) error {
+		ran = true
+		return nil
+	}})
+	if err := l.RunAndWait(); err == nil {
+		t.Error("expected an error")
+	}
+
+	for i, e := range []TaskStatus{TaskFailed, TaskSkipped, TaskSkipped, TaskCompleted} {
+		if s := l.Tasks[i].GetStatus(); s != e {
+			t.Errorf("expected task %d to be %q, got %q", i, e, s)
+		}
+	}
+	if !ran {
+		t.Error("expected the independent task to run")
+	}
+}
+
+func TestList_RunGraphCycle(t *testing.T) {
+	var ran bool
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.AddTask(&Task{ID: "a", Message: "a", Action: func(c TaskContext) error {
+		ran = true
+		return nil
+	}})
+	l.AddTask(&Task{ID: "b", DependsOn: []string{"c"}, Message: "b", Action: func(c TaskContext) error { return nil }})
+	l.AddTask(&Task{ID: "c", DependsOn: []string{"b"}, Message: "c", Action: func(c TaskContext) error { return nil }})
+	if err := l.RunAndWait(); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected error %q, got %q", ErrDependencyCycle, err)
+	}
+	if ran {
+		t.Error("no tasks should run when there's a cycle")
+	}
+}
+
+func TestTaskGroup_RunGraphFailOnError(t *testing.T) {
+	var ran bool
+	g := &TaskGroup{
+		Message:     "g",
+		FailOnError: true,
+		Tasks: []TaskRunner{
+			&Task{ID: "a", Message: "a", Action: func(c TaskContext) error {
+				return errors.New("oh no")
+			}},
+			&Task{ID: "b", Message: "b", Action: func(c TaskContext) error {
+				time.Sleep(time.Millisecond * 20)
+				return nil
+			}},
+			&Task{ID: "c", Message: "c", DependsOn: []string{"b"}, Action: func(c TaskContext) error {
+				ran = true
+				return nil
+			}},
+		},
+	}
+	if err := g.Run(&taskContext{}); err == nil {
+		t.Error("expected an error")
+	}
+	if ran {
+		t.Error("expected c to be skipped after a failed")
+	}
+	if s := g.Tasks[2].GetStatus(); s != TaskSkipped {
+		t.Errorf("expected c to be %q, got %q", TaskSkipped, s)
+	}
+}
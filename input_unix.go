@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package golist
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// canPollInput is true if waitForInput can wait with a timeout
+const canPollInput = true
+
+// waitForInput waits up to `timeout` for the file `f`
+// to have input to read, and returns true if it does
+func waitForInput(f *os.File, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err == unix.EINTR {
+		return false, nil
+	}
+	return n > 0, err
+}
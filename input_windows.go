@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package golist
+
+import (
+	"os"
+	"time"
+)
+
+// canPollInput is true if waitForInput can wait with a timeout
+const canPollInput = false
+
+// waitForInput always returns true, since the console's input
+// can't be polled, so the next read blocks until there's input
+func waitForInput(f *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}
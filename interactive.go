@@ -0,0 +1,332 @@
+package golist
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// stdin is the terminal that an interactive
+// List reads key presses from
+var stdin = os.Stdin
+
+// inputPollInterval is how often the keyboard
+// reader checks whether it should stop
+const inputPollInterval = 100 * time.Millisecond
+
+// maxLogLines is the maximum number of a task's
+// printed lines to show when its log is shown
+const maxLogLines = 10
+
+// key is a key press that controls an interactive List
+type key int
+
+const (
+	keyQuit   key = iota // "q" or Ctrl-C: cancel the run
+	keyPause             // "p": pause or resume starting new tasks
+	keyExpand            // "e": expand or collapse the selected task group
+	keyLog               // "l": show or hide the selected task's printed lines
+	keyUp                // "k" or the up arrow: select the previous task
+	keyDown              // "j" or the down arrow: select the next task
+)
+
+// parseKeys returns the keys pressed in the terminal
+// input `b`, ignoring any that don't control the List
+func parseKeys(b []byte) []key {
+	var keys []key
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case 'q', 0x03:
+			keys = append(keys, keyQuit)
+		case 'p':
+			keys = append(keys, keyPause)
+		case 'e':
+			keys = append(keys, keyExpand)
+		case 'l':
+			keys = append(keys, keyLog)
+		case 'k':
+			keys = append(keys, keyUp)
+		case 'j':
+			keys = append(keys, keyDown)
+		case 0x1b:
+			// The arrow keys are sent as "ESC [ A" or "ESC O A"
+			if i+2 >= len(b) || (b[i+1] != '[' && b[i+1] != 'O') {
+				continue
+			}
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			}
+			i += 2
+		}
+	}
+	return keys
+}
+
+// keyboard reads key presses from a terminal in raw mode
+type keyboard struct {
+	f     *os.File      // The terminal
+	state *term.State   // The terminal's state before it was put in raw mode
+	keys  chan key      // The keys pressed
+	stop  chan struct{} // Closed to stop reading
+	done  chan struct{} // Closed once the reader has stopped
+}
+
+// openKeyboard puts the terminal `f` in raw mode,
+// so each key press can be read as it happens, and
+// starts reading key presses from it.
+func openKeyboard(f *os.File) (*keyboard, error) {
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	k := &keyboard{
+		f:     f,
+		state: state,
+		keys:  make(chan key),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go k.read()
+	return k, nil
+}
+
+// read sends the keys pressed to the keys
+// channel until the keyboard is closed
+func (k *keyboard) read() {
+	defer close(k.done)
+	buf := make([]byte, 64)
+	for {
+		select {
+		case <-k.stop:
+			return
+		default:
+		}
+
+		// Only read once there's input, so that
+		// closing the keyboard isn't held up
+		ready, err := waitForInput(k.f, inputPollInterval)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+
+		n, err := k.f.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			select {
+			case k.keys <- key:
+			case <-k.stop:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// close stops reading key presses and restores the terminal
+func (k *keyboard) close() {
+	if k == nil {
+		return
+	}
+	close(k.stop)
+	if canPollInput {
+		<-k.done
+	}
+	term.Restore(int(k.f.Fd()), k.state)
+}
+
+// outputter is implemented by TaskRunners that keep
+// the lines printed through their TaskContext
+type outputter interface {
+	getOutput() []string
+}
+
+// controls is what an interactive List's keyboard controls. It
+// keeps track of the selected task, the collapsed task groups,
+// and the tasks whose printed lines are shown, and it applies
+// them to the task states before they're drawn.
+//
+// The controls are only used from the List's display goroutine.
+type controls struct {
+	l         *List                     // The list being controlled
+	selected  TaskRunner                // The selected task
+	rows      []TaskRunner              // The tasks in the last view, in order, for moving the selection
+	parents   map[TaskRunner]*TaskGroup // The group each task in the last view belongs to
+	collapsed map[TaskRunner]bool       // The task groups whose tasks are hidden
+	logs      map[TaskRunner]bool       // The tasks whose printed lines are shown
+}
+
+// newControls creates the controls for the List `l`
+func newControls(l *List) *controls {
+	return &controls{
+		l:         l,
+		parents:   make(map[TaskRunner]*TaskGroup),
+		collapsed: make(map[TaskRunner]bool),
+		logs:      make(map[TaskRunner]bool),
+	}
+}
+
+// press handles the key press `k`
+func (c *controls) press(k key) {
+	switch k {
+	case keyQuit:
+		c.l.interrupt()
+
+	case keyPause:
+		if c.l.Paused() {
+			c.l.Resume()
+		} else {
+			c.l.Pause()
+		}
+
+	case keyExpand:
+		// Toggle the selected group, or collapse
+		// the group the selected task belongs to
+		if _, ok := c.selected.(*TaskGroup); ok {
+			c.collapsed[c.selected] = !c.collapsed[c.selected]
+		} else if g := c.parents[c.selected]; g != nil {
+			c.collapsed[g] = true
+			c.selected = g
+		}
+
+	case keyLog:
+		if _, ok := c.selected.(outputter); ok {
+			c.logs[c.selected] = !c.logs[c.selected]
+		}
+
+	case keyUp, keyDown:
+		for i, r := range c.rows {
+			if r != c.selected {
+				continue
+			}
+			if k == keyUp && i > 0 {
+				c.selected = c.rows[i-1]
+			} else if k == keyDown && i < len(c.rows)-1 {
+				c.selected = c.rows[i+1]
+			}
+			break
+		}
+	}
+}
+
+// view applies the controls to the task states: the tasks in
+// collapsed groups are hidden, the selected task is marked,
+// and the lines printed by the tasks whose logs are shown are
+// added below them.
+func (c *controls) view(states []*TaskState) []*TaskState {
+	var view []*TaskState
+	c.rows = c.rows[:0]
+	c.parents = make(map[TaskRunner]*TaskGroup)
+
+	var groups []*TaskState // The groups that contain the current state
+	var folded *TaskState   // The collapsed group whose tasks are being hidden
+	var hidden int          // The number of tasks hidden in `folded`
+	fold := func() {
+		if folded != nil && hidden > 0 {
+			folded.Message += fmt.Sprintf(" (%d hidden)", hidden)
+		}
+		folded, hidden = nil, 0
+	}
+
+	var logged bool // Is the last task's log being shown?
+	for _, s := range states {
+		// Hide the tasks in collapsed groups
+		if folded != nil && s.Depth > folded.Depth {
+			if !s.logLine {
+				hidden++
+			}
+			continue
+		}
+		fold()
+
+		// The task's log replaces its tail
+		if s.logLine && logged {
+			continue
+		}
+		logged = false
+
+		// Keep track of the group each task belongs to
+		for len(groups) > 0 && groups[len(groups)-1].Depth >= s.Depth {
+			groups = groups[:len(groups)-1]
+		}
+		if len(groups) > 0 && s.runner != nil {
+			if g, ok := groups[len(groups)-1].runner.(*TaskGroup); ok {
+				c.parents[s.runner] = g
+			}
+		}
+		groups = append(groups, s)
+
+		view = append(view, s)
+		if s.runner == nil {
+			continue
+		}
+		c.rows = append(c.rows, s.runner)
+		if c.collapsed[s.runner] {
+			folded = s
+		}
+		if c.logs[s.runner] {
+			view = append(view, logRows(s)...)
+			logged = true
+		}
+	}
+	fold()
+
+	// Keep the selection on a task that's shown
+	if !c.isShown(c.selected) {
+		c.selected = nil
+		if len(c.rows) > 0 {
+			c.selected = c.rows[0]
+		}
+	}
+	for _, s := range view {
+		if s.runner != nil && s.runner == c.selected {
+			s.selected = true
+		}
+	}
+	return view
+}
+
+// isShown returns true if the task `r` was in the last view
+func (c *controls) isShown(r TaskRunner) bool {
+	for _, row := range c.rows {
+		if row == r {
+			return true
+		}
+	}
+	return false
+}
+
+// logRows returns rows for the last lines printed by
+// the task with the state `s`, to show below it
+func logRows(s *TaskState) []*TaskState {
+	o, ok := s.runner.(outputter)
+	if !ok {
+		return nil
+	}
+	lines := o.getOutput()
+	if len(lines) == 0 {
+		lines = []string{"(no output)"}
+	}
+	if len(lines) > maxLogLines {
+		n := len(lines) - maxLogLines + 1 // Leave room for the summary line
+		lines = append([]string{fmt.Sprintf("… %d earlier lines", n)}, lines[n:]...)
+	}
+	return outputRows(s, lines)
+}
+
+// footer returns the line drawn below the list,
+// describing the keys that control it
+func (c *controls) footer() string {
+	if c.l.Paused() {
+		return "Paused · p resume · j/k select · e expand/collapse · l log · q quit"
+	}
+	return "p pause · j/k select · e expand/collapse · l log · q quit"
+}
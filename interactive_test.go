@@ -0,0 +1,329 @@
+package golist
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := map[string][]key{
+		"q":             {keyQuit},
+		"\x03":          {keyQuit},
+		"pel":           {keyPause, keyExpand, keyLog},
+		"jk":            {keyDown, keyUp},
+		"\x1b[A\x1b[B":  {keyUp, keyDown},
+		"\x1bOA":        {keyUp},
+		"x\x1b[Cz\x1b":  nil,
+		"\x1b[A!\x1bOB": {keyUp, keyDown},
+	}
+	for in, expect := range cases {
+		if keys := parseKeys([]byte(in)); !reflect.DeepEqual(keys, expect) {
+			t.Errorf("%q: expected %v, got %v", in, expect, keys)
+		}
+	}
+}
+
+// newControlsList creates a List, for testing its controls, with
+// a task, a group of two tasks, and another task
+func newControlsList() (*List, *Task, *TaskGroup, *Task) {
+	first := NewTask("first", nil)
+	g := NewTaskGroup("group", []TaskRunner{
+		NewTask("a", nil),
+		NewTask("b", nil),
+	})
+	last := NewTask("last", nil)
+	l := NewList()
+	l.AddTask(first).AddTask(g).AddTask(last)
+	return l, first, g, last
+}
+
+// viewMessages returns the messages of the rows in the
+// controls' view, with a "*" before the selected row
+func viewMessages(c *controls) []string {
+	var msgs []string
+	for _, s := range c.view(c.l.getTaskStates()) {
+		m := strings.Repeat(" ", s.Depth) + s.Message
+		if s.selected {
+			m = "*" + m
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+func TestControls_Select(t *testing.T) {
+	l, _, _, _ := newControlsList()
+	c := newControls(l)
+
+	// The first task is selected to start with
+	expect := []string{"*first", "group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+
+	c.press(keyDown)
+	c.press(keyDown)
+	expect = []string{"first", "group", "* a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+
+	// The selection stops at the ends
+	c.press(keyUp)
+	c.press(keyUp)
+	c.press(keyUp)
+	expect = []string{"*first", "group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+}
+
+func TestControls_Expand(t *testing.T) {
+	l, _, g, _ := newControlsList()
+	c := newControls(l)
+	viewMessages(c)
+
+	// Collapse the group from one of its tasks
+	c.press(keyDown)
+	c.press(keyDown)
+	viewMessages(c)
+	c.press(keyExpand)
+	expect := []string{"first", "*group (2 hidden)", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+	if c.selected != g {
+		t.Errorf("expected the group to be selected")
+	}
+
+	// Expand it again
+	c.press(keyExpand)
+	expect = []string{"first", "*group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+
+	// Tasks at the top level have no group to collapse
+	c.press(keyUp)
+	viewMessages(c)
+	c.press(keyExpand)
+	expect = []string{"*first", "group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+}
+
+func TestControls_Log(t *testing.T) {
+	l, first, _, _ := newControlsList()
+	first.addOutput("hello")
+	first.addOutput("world")
+	c := newControls(l)
+	viewMessages(c)
+
+	c.press(keyLog)
+	states := c.view(l.getTaskStates())
+	if !states[1].logLine || !states[2].logLine || states[3].logLine {
+		t.Fatalf("expected the task's lines to be shown below it")
+	}
+	expect := []string{"*first", " hello", " world", "group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+
+	// Hide them again
+	c.press(keyLog)
+	expect = []string{"*first", "group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+
+	// Groups don't have a log
+	c.press(keyDown)
+	viewMessages(c)
+	c.press(keyLog)
+	expect = []string{"first", "*group", " a", " b", "last"}
+	if msgs := viewMessages(c); !reflect.DeepEqual(msgs, expect) {
+		t.Errorf("expected %q, got %q", expect, msgs)
+	}
+}
+
+func TestLogRows(t *testing.T) {
+	task := NewTask("t", nil)
+	s := task.GetTaskStates()[0]
+	if rows := logRows(s); len(rows) != 1 || rows[0].Message != "(no output)" {
+		t.Errorf("expected a row saying there's no output, got %v", rows)
+	}
+
+	for i := 0; i < maxLogLines+5; i++ {
+		task.addOutput(strings.Repeat("x", i))
+	}
+	rows := logRows(s)
+	if len(rows) != maxLogLines {
+		t.Fatalf("expected %d rows, got %d", maxLogLines, len(rows))
+	}
+	if e := "… 6 earlier lines"; rows[0].Message != e {
+		t.Errorf("expected %q, got %q", e, rows[0].Message)
+	}
+	if e := strings.Repeat("x", maxLogLines+4); rows[len(rows)-1].Message != e {
+		t.Errorf("expected the last line to be %q, got %q", e, rows[len(rows)-1].Message)
+	}
+}
+
+func TestControls_PauseAndQuit(t *testing.T) {
+	started := make(chan struct{})
+	l := NewListWithWriter(&strings.Builder{})
+	l.Display = DisplayLog
+	l.AddTask(NewTask("wait", func(c TaskContext) error {
+		close(started)
+		<-c.Context().Done()
+		return c.Context().Err()
+	}))
+	c := newControls(l)
+
+	c.press(keyPause)
+	if !l.Paused() {
+		t.Error("expected the list to be paused")
+	}
+	c.press(keyPause)
+	if l.Paused() {
+		t.Error("expected the list to be resumed")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- l.RunAndWait() }()
+	<-started
+	c.press(keyQuit)
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected the run to be cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected quitting to cancel the run")
+	}
+}
+
+func TestTerminalRenderer_Controls(t *testing.T) {
+	r, s := newTestRenderer()
+	first, second := NewTask("first", nil), NewTask("second", nil)
+	r.l.AddTask(first).AddTask(second)
+	r.controls = newControls(r.l)
+
+	r.render(r.l.getTaskStates())
+	lines := strings.Split(s.String(), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[2], "p pause") {
+		t.Errorf("expected the keys to be described below the list, got %q", lines)
+	}
+	if !strings.Contains(r.frame[0], ansiReverse) || strings.Contains(r.frame[1], ansiReverse) {
+		t.Errorf("expected the selected task to be highlighted, got %q", r.frame)
+	}
+
+	// Pausing is shown in the footer
+	r.controls.press(keyPause)
+	r.render(r.l.getTaskStates())
+	if lines := strings.Split(s.String(), "\n"); !strings.HasPrefix(lines[2], "Paused") {
+		t.Errorf("expected the list to be shown as paused, got %q", lines)
+	}
+	r.l.Resume()
+
+	// The final frame doesn't have the controls
+	r.finish(r.l.getTaskStates(), false)
+	if e := "– first\n– second"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+	for _, line := range r.frame {
+		if strings.Contains(line, ansiReverse) {
+			t.Errorf("expected no highlighting in the final frame, got %q", line)
+		}
+	}
+}
+
+func TestTerminalRenderer_Raw(t *testing.T) {
+	var b strings.Builder
+	r := newTerminalRenderer(NewList(), &b)
+	r.raw = true
+	r.println("hello")
+	if out := b.String(); !strings.Contains(out, "hello\r\n") || strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Errorf("expected newlines to be written as \"\\r\\n\", got %q", out)
+	}
+}
+
+func TestKeyboard_Read(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("reading from a pipe blocks on windows")
+	}
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+
+	k := &keyboard{
+		f:    pr,
+		keys: make(chan key),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go k.read()
+
+	pw.Write([]byte("j\x1b[Aq"))
+	var keys []key
+	for len(keys) < 3 {
+		select {
+		case key := <-k.keys:
+			keys = append(keys, key)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected 3 keys, got %v", keys)
+		}
+	}
+	if expect := []key{keyDown, keyUp, keyQuit}; !reflect.DeepEqual(keys, expect) {
+		t.Errorf("expected %v, got %v", expect, keys)
+	}
+
+	// The reader stops without any more input
+	close(k.stop)
+	select {
+	case <-k.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the reader to stop")
+	}
+}
+
+func TestList_InteractiveWithoutTerminal(t *testing.T) {
+	ctx := context.Background()
+	var b strings.Builder
+	l := NewListWithWriter(&b)
+	l.Display = DisplayTerminal
+	l.Interactive = true
+	l.AddTask(NewTask("t", func(c TaskContext) error { return nil }))
+	if err := l.RunAndWaitContext(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(b.String(), "q quit") {
+		t.Errorf("expected no keyboard controls when stdin isn't a terminal, got %q", b.String())
+	}
+}
+
+func TestControls_LogReplacesTail(t *testing.T) {
+	l, first, _, _ := newControlsList()
+	first.SetStatus(TaskInProgress)
+	first.addOutput("hello")
+	c := newControls(l)
+	states := withTails(l.getTaskStates(), 5)
+	c.view(states)
+
+	c.press(keyLog)
+	var msgs []string
+	for _, s := range c.view(withTails(l.getTaskStates(), 5)) {
+		msgs = append(msgs, strings.Repeat(" ", s.Depth)+s.Message)
+	}
+	if e := []string{"first", " hello", "group", " a", " b", "last"}; !reflect.DeepEqual(msgs, e) {
+		t.Errorf("expected %q, got %q", e, msgs)
+	}
+}
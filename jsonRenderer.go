@@ -0,0 +1,99 @@
+package golist
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonEvent is the JSON object written for each event by
+// the jsonRenderer. Durations are in seconds.
+type jsonEvent struct {
+	Type     string        `json:"type"`
+	Time     time.Time     `json:"time"`
+	ID       string        `json:"id,omitempty"`
+	Path     []string      `json:"path,omitempty"`
+	Status   string        `json:"status,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	Duration float64       `json:"duration,omitempty"`
+	Progress *jsonProgress `json:"progress,omitempty"`
+	Line     string        `json:"line,omitempty"`
+	Attempt  int           `json:"attempt,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// jsonProgress is the JSON object for a task's Progress
+type jsonProgress struct {
+	Current int64   `json:"current"`
+	Total   int64   `json:"total,omitempty"`
+	Rate    float64 `json:"rate,omitempty"`
+	ETA     float64 `json:"eta,omitempty"`
+}
+
+// jsonRenderer is a renderer that writes each of the List's
+// events as a line of JSON (see `DisplayJSON`). It's sent the
+// events as an Observer, so its renderer methods do nothing.
+type jsonRenderer struct {
+	enc *json.Encoder
+}
+
+// newJSONRenderer creates a jsonRenderer
+// that writes the events to `w`
+func newJSONRenderer(w io.Writer) *jsonRenderer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonRenderer{enc: enc}
+}
+
+// OnEvent writes the event `e` as a line of JSON
+func (r *jsonRenderer) OnEvent(e Event) {
+	je := jsonEvent{
+		Type:     e.Type.String(),
+		Time:     e.Time,
+		ID:       e.ID,
+		Path:     e.Path,
+		Message:  e.Message,
+		Duration: e.Elapsed.Seconds(),
+		Line:     e.Line,
+		Attempt:  e.Attempt,
+	}
+	if e.Task != nil {
+		je.Status = e.Status.String()
+	}
+	if p := e.Progress; p != nil {
+		je.Progress = &jsonProgress{
+			Current: p.Current,
+			Total:   p.Total,
+			Rate:    p.Rate,
+			ETA:     p.ETA.Seconds(),
+		}
+	}
+	if e.Err != nil {
+		je.Error = e.Err.Error()
+	}
+	r.enc.Encode(je)
+}
+
+// writeJSONSummary writes the List's summary `s` to `w` as a line
+// of JSON with the type "Summary", and the summary as its message
+func writeJSONSummary(w io.Writer, s string) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(jsonEvent{
+		Type:    "Summary",
+		Time:    time.Now(),
+		Message: s,
+	})
+}
+
+// render does nothing, since the events are written as they happen
+func (r *jsonRenderer) render(states []*TaskState) {}
+
+// println does nothing, since printed lines are written as events
+func (r *jsonRenderer) println(s string) {}
+
+// finish does nothing, since the events are written as they happen
+func (r *jsonRenderer) finish(states []*TaskState, clear bool) {}
+
+// resize does nothing, since the output isn't drawn in place
+func (r *jsonRenderer) resize() {}
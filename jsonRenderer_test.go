@@ -0,0 +1,66 @@
+package golist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestList_JSONOutput(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Display = DisplayJSON
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		&Task{ID: "api", Message: "Deploy the API", Action: func(c TaskContext) error {
+			c.Println("deploying")
+			c.SetProgress(1, 4)
+			return errors.New("exit status 1")
+		}},
+	}))
+	l.Start()
+	if err := l.Println("between tasks"); err != nil {
+		t.Fatal(err)
+	}
+	l.Run()
+	l.Stop()
+
+	if strings.Contains(w.String(), "\033") {
+		t.Errorf("expected no ANSI escape characters, got %q", w.String())
+	}
+
+	var events []jsonEvent
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var e jsonEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %s", line, err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("expected a timestamp in %q", line)
+		}
+		events = append(events, e)
+	}
+
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	e := "ListStarted Println TaskStarted TaskStarted Println Progress TaskFailed TaskFailed ListStopped"
+	if s := strings.Join(types, " "); s != e {
+		t.Fatalf("expected events %q, got %q", e, s)
+	}
+
+	if e := events[1]; e.Line != "between tasks" || e.Path != nil {
+		t.Errorf("expected the list's printed line without a path, got %+v", e)
+	}
+	if e := events[4]; e.Line != "deploying" || strings.Join(e.Path, "/") != "deploy/api" || e.ID != "api" {
+		t.Errorf("expected the task's printed line with its ID and path, got %+v", e)
+	}
+	if e := events[5]; e.Progress == nil || e.Progress.Current != 1 || e.Progress.Total != 4 {
+		t.Errorf("expected progress of 1/4, got %+v", e.Progress)
+	}
+	if e := events[6]; e.Status != "Failed" || e.Error != "exit status 1" || e.Message != "Deploy the API" || e.Duration <= 0 {
+		t.Errorf("expected the task's failure, got %+v", e)
+	}
+}
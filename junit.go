@@ -0,0 +1,201 @@
+package golist
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JUnitReporter writes the results of a finished List as JUnit
+// XML, for CI systems that understand JUnit test reports.
+//
+// Each TaskGroup becomes a testsuite (named with its path, e.g.
+// "deploy › services", with a number added if sibling groups have
+// the same path) and each Task becomes a testcase in its group's
+// testsuite. Tasks that aren't in a group go in a testsuite
+// named after the reporter. Failed tasks get a failure with their
+// error, skipped tasks are marked as skipped, and the lines a task
+// printed through its TaskContext go in its system-out.
+//
+// To capture printed lines, add the reporter to the List as an
+// Observer before it runs:
+//
+//	r := golist.NewJUnitReporter("deploy")
+//	l.AddObserver(r)
+//	l.RunAndWait()
+//	r.Write(f, l)
+type JUnitReporter struct {
+	Name string // The name of the report, and of the testsuite for tasks that aren't in a group
+
+	mu     sync.Mutex
+	output map[TaskRunner][]string // The lines printed by each task
+}
+
+// NewJUnitReporter creates a JUnitReporter with the name `name`
+func NewJUnitReporter(name string) *JUnitReporter {
+	return &JUnitReporter{
+		Name:   name,
+		output: make(map[TaskRunner][]string),
+	}
+}
+
+// OnEvent records the lines printed by each task
+func (r *JUnitReporter) OnEvent(e Event) {
+	if e.Type != EventPrintln || e.Task == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.output == nil {
+		r.output = make(map[TaskRunner][]string)
+	}
+	r.output[e.Task] = append(r.output[e.Task], e.Line)
+}
+
+// junitTestSuites is the root element of a JUnit report
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr,omitempty"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a testsuite element, for a TaskGroup
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a testcase element, for a Task
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure is the failure element of a failed testcase
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Write writes the results of the finished List `l` to `w` as JUnit XML
+func (r *JUnitReporter) Write(w io.Writer, l *List) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(r.report(l)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// report builds the JUnit report for the List `l`
+func (r *JUnitReporter) report(l *List) *junitTestSuites {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nodes := walkTasks(l.Tasks, nil)
+	root := &junitTestSuites{
+		Name: r.Name,
+		Time: fmtSeconds(wallTime(nodes)),
+	}
+
+	// Create a testsuite for the tasks that aren't in a group, and
+	// for each group, in tree order (skipping any that are empty)
+	rootSuite := &junitTestSuite{Name: r.Name, Time: root.Time}
+	suites := map[TaskRunner]*junitTestSuite{nil: rootSuite}
+	order := []*junitTestSuite{rootSuite}
+	names := make(map[string]int) // The number of groups with each path so far
+	for _, n := range nodes {
+		if n.group {
+			// Groups are told apart by the TaskRunner, since
+			// sibling groups can have the same path
+			name := fmtPath(n.path)
+			if names[name]++; names[name] > 1 {
+				name = fmt.Sprintf("%s (%d)", name, names[name])
+			}
+			s := &junitTestSuite{
+				Name: name,
+				Time: fmtSeconds(n.state.Elapsed),
+			}
+			suites[n.runner] = s
+			order = append(order, s)
+		}
+	}
+
+	for _, n := range nodes {
+		// A group's own error (e.g. from timing out)
+		// is reported as a testcase of its own
+		if n.group && n.err == nil {
+			continue
+		}
+		parent := n.parent
+		if n.group {
+			parent = n.runner
+		}
+		s := suites[parent]
+
+		tc := &junitTestCase{
+			Name:      n.path[len(n.path)-1],
+			ClassName: s.Name,
+			Time:      fmtSeconds(n.state.Elapsed),
+			SystemOut: strings.Join(r.output[n.runner], "\n"),
+		}
+		switch {
+		case n.err != nil:
+			msg := n.err.Error()
+			tc.Failure = &junitFailure{
+				Message: strings.SplitN(msg, "\n", 2)[0],
+				Type:    n.state.Status.String(),
+				Text:    msg,
+			}
+			var pe *PanicError
+			if errors.As(n.err, &pe) {
+				tc.Failure.Text += "\n\n" + string(pe.Stack)
+			}
+			s.Failures++
+		case n.state.Status == TaskSkipped || n.state.Status == TaskNotStarted:
+			// Tasks that never started were skipped along with their group
+			tc.Skipped = &struct{}{}
+			s.Skipped++
+		}
+		s.Tests++
+		s.Cases = append(s.Cases, tc)
+	}
+
+	for _, s := range order {
+		if s.Tests == 0 {
+			continue
+		}
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Skipped += s.Skipped
+		root.Suites = append(root.Suites, s)
+	}
+	return root
+}
+
+// fmtSeconds formats a duration as a number
+// of seconds, for JUnit's time attributes
+func fmtSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
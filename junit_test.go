@@ -0,0 +1,129 @@
+package golist
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.AddTask(NewTask("build", func(c TaskContext) error {
+		c.Println("compiling")
+		c.Println("linking")
+		return nil
+	}))
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		&Task{ID: "api", Message: "Deploy the API", Action: func(c TaskContext) error {
+			return errors.New("exit status 1\nconnection refused")
+		}},
+		&Task{Message: "web", Skip: func(c TaskContext) bool { return true }},
+		NewTaskGroup("checks", []TaskRunner{
+			NewTask("health", func(c TaskContext) error { return nil }),
+		}),
+	}))
+
+	r := NewJUnitReporter("golist")
+	l.AddObserver(r)
+	l.RunAndWait()
+
+	w := &bytes.Buffer{}
+	if err := r.Write(w, l); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(w.String(), xml.Header) {
+		t.Errorf("expected the XML header, got %q", w.String())
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(w.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, w.String())
+	}
+	if report.Tests != 4 || report.Failures != 1 || report.Skipped != 1 {
+		t.Errorf("expected 4 tests, 1 failure, and 1 skipped, got %d, %d, and %d", report.Tests, report.Failures, report.Skipped)
+	}
+
+	names := make([]string, len(report.Suites))
+	for i, s := range report.Suites {
+		names[i] = s.Name
+	}
+	if s, e := strings.Join(names, ", "), "golist, deploy, deploy › checks"; s != e {
+		t.Fatalf("expected testsuites %q, got %q", e, s)
+	}
+
+	build := report.Suites[0].Cases[0]
+	if build.Name != "build" || build.SystemOut != "compiling\nlinking" {
+		t.Errorf("expected the build testcase with its output, got %+v", build)
+	}
+
+	deploy := report.Suites[1]
+	if len(deploy.Cases) != 2 {
+		t.Fatalf("expected 2 testcases in deploy, got %d", len(deploy.Cases))
+	}
+	api := deploy.Cases[0]
+	if api.Name != "api" || api.ClassName != "deploy" || api.Failure == nil {
+		t.Fatalf("expected the api testcase to fail, got %+v", api)
+	}
+	if api.Failure.Message != "exit status 1" || api.Failure.Text != "exit status 1\nconnection refused" || api.Failure.Type != "Failed" {
+		t.Errorf("unexpected failure %+v", api.Failure)
+	}
+	if web := deploy.Cases[1]; web.Skipped == nil {
+		t.Errorf("expected the web testcase to be skipped, got %+v", web)
+	}
+	if health := report.Suites[2].Cases[0]; health.Name != "health" || health.Failure != nil || health.Skipped != nil {
+		t.Errorf("expected the health testcase to pass, got %+v", health)
+	}
+}
+
+func TestJUnitReporter_GroupError(t *testing.T) {
+	g := NewTaskGroup("deploy", []TaskRunner{
+		&Task{ID: "a", DependsOn: []string{"missing"}, Action: func(c TaskContext) error { return nil }},
+	})
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.AddTask(g)
+	g.Run(&taskContext{})
+
+	report := NewJUnitReporter("golist").report(l)
+	if len(report.Suites) != 1 || len(report.Suites[0].Cases) != 2 {
+		t.Fatalf("expected a testsuite with 2 testcases, got %+v", report.Suites)
+	}
+	if tc := report.Suites[0].Cases[0]; tc.Name != "deploy" || tc.Failure == nil || !strings.Contains(tc.Failure.Message, "unknown dependency") {
+		t.Errorf("expected the group's error as a testcase, got %+v", tc)
+	}
+	if tc := report.Suites[0].Cases[1]; tc.Skipped == nil {
+		t.Errorf("expected the task that never ran to be skipped, got %+v", tc)
+	}
+}
+
+func TestJUnitReporter_SameNames(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.Concurrent = true
+	for i := 0; i < 3; i++ {
+		l.AddTask(NewTaskGroup("group", []TaskRunner{
+			NewTask("a", func(c TaskContext) error { return nil }),
+			NewTask("b", func(c TaskContext) error { return nil }),
+		}))
+	}
+	l.RunAndWait()
+
+	// Each group gets its own testsuite, with a unique name
+	report := NewJUnitReporter("golist").report(l)
+	var names []string
+	for _, s := range report.Suites {
+		names = append(names, s.Name)
+		if s.Tests != 2 || len(s.Cases) != 2 {
+			t.Errorf("expected 2 testcases in %q, got %d", s.Name, s.Tests)
+		}
+	}
+	if e := "group,group (2),group (3)"; strings.Join(names, ",") != e {
+		t.Errorf("expected testsuites %q, got %q", e, names)
+	}
+	if report.Tests != 6 {
+		t.Errorf("expected 6 tests, got %d", report.Tests)
+	}
+}
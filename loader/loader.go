@@ -0,0 +1,250 @@
+// Package loader builds golist Lists from declarative
+// task files, written in YAML or JSON.
+//
+// A task file describes a list of tasks. Each task either runs
+// a shell command (`run`), runs a command directly (`command`),
+// or is a group of other tasks (`tasks`):
+//
+//	concurrent: false
+//	fail_on_error: true
+//	env:
+//	  GOFLAGS: -mod=mod
+//	tasks:
+//	  - message: Build
+//	    run: go build ./...
+//	  - message: Test
+//	    command: [go, test, ./...]
+//	    skip_if: test -n "$SKIP_TESTS"
+//	  - message: Deploy
+//	    concurrent: true
+//	    dir: ./deploy
+//	    tasks:
+//	      - message: API
+//	        run: ./deploy.sh api
+//	      - message: Web
+//	        run: ./deploy.sh web
+//
+// Environment variables and working directories are inherited by
+// nested tasks. Relative directories are resolved from the parent's
+// directory, starting from the task file's directory.
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/a-poor/golist"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNoAction is returned when a task doesn't
+	// have a `run`, `command`, or `tasks` field
+	ErrNoAction = errors.New("task needs one of run, command, or tasks")
+
+	// ErrMultipleActions is returned when a task has more
+	// than one of the `run`, `command`, and `tasks` fields
+	ErrMultipleActions = errors.New("task can only have one of run, command, or tasks")
+)
+
+// File is the contents of a task file
+type File struct {
+	Concurrent     bool              `yaml:"concurrent"`      // Should the tasks be run concurrently?
+	FailOnError    bool              `yaml:"fail_on_error"`   // Should the run stop on the first error?
+	MaxConcurrency int               `yaml:"max_concurrency"` // Maximum number of tasks to run at once when running concurrently (0 = no limit)
+	Env            map[string]string `yaml:"env"`             // Environment variables for all of the commands
+	Dir            string            `yaml:"dir"`             // Working directory for all of the commands
+	Tasks          []Task            `yaml:"tasks"`           // The tasks to run
+}
+
+// Task is a task in a task file: a command, or a group of tasks
+type Task struct {
+	Message        string            `yaml:"message"`         // The message to display (defaults to the command)
+	Run            string            `yaml:"run"`             // A shell command to run
+	Command        []string          `yaml:"command"`         // A command and its arguments to run directly, without a shell
+	Env            map[string]string `yaml:"env"`             // Environment variables for the command (or the group's commands)
+	Dir            string            `yaml:"dir"`             // Working directory for the command (or the group's commands)
+	Skip           bool              `yaml:"skip"`            // If true, the task is skipped
+	SkipIf         string            `yaml:"skip_if"`         // A shell command that's run first. If it succeeds, the task is skipped
+	Concurrent     bool              `yaml:"concurrent"`      // For groups, should the tasks be run concurrently?
+	FailOnError    bool              `yaml:"fail_on_error"`   // For groups, should the group stop on the first error?
+	MaxConcurrency int               `yaml:"max_concurrency"` // For groups, the maximum number of tasks to run at once when running concurrently
+	Tasks          []Task            `yaml:"tasks"`           // For groups, the tasks in the group
+}
+
+// Load reads the task file at `path` and builds a List from it.
+// Relative directories are resolved from the file's directory.
+func Load(path string) (*golist.List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	l, err := f.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Parse parses a task file, written in YAML or JSON.
+// Unknown fields are reported as errors.
+func Parse(data []byte) (*File, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// List builds a List from the task file. Relative
+// directories are resolved from the directory `dir`.
+func (f *File) List(dir string) (*golist.List, error) {
+	l := golist.NewList()
+	l.Concurrent = f.Concurrent
+	l.FailOnError = f.FailOnError
+	l.MaxConcurrency = f.MaxConcurrency
+
+	scope := scope{dir: resolveDir(dir, f.Dir)}.withEnv(f.Env)
+	for i, t := range f.Tasks {
+		r, err := t.build(scope)
+		if err != nil {
+			return nil, fmt.Errorf("tasks[%d]: %w", i, err)
+		}
+		l.AddTask(r)
+	}
+	return l, nil
+}
+
+// scope is the environment and working
+// directory that a task inherits
+type scope struct {
+	env []string // Environment variables ("KEY=value")
+	dir string   // Working directory
+}
+
+// withEnv returns a copy of the scope with the environment
+// variables `env` added, in a consistent order
+func (s scope) withEnv(env map[string]string) scope {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s.env = append([]string{}, s.env...)
+	for _, k := range keys {
+		s.env = append(s.env, k+"="+env[k])
+	}
+	return s
+}
+
+// build creates the TaskRunner for the task
+func (t Task) build(parent scope) (golist.TaskRunner, error) {
+	var n int
+	for _, set := range []bool{t.Run != "", len(t.Command) > 0, len(t.Tasks) > 0} {
+		if set {
+			n++
+		}
+	}
+	switch {
+	case n == 0:
+		return nil, ErrNoAction
+	case n > 1:
+		return nil, ErrMultipleActions
+	}
+
+	s := scope{env: parent.env, dir: resolveDir(parent.dir, t.Dir)}.withEnv(t.Env)
+	skip := t.skipFunc(s)
+
+	// Build a group...
+	if len(t.Tasks) > 0 {
+		g := golist.NewTaskGroup(t.Message, nil)
+		g.Concurrent = t.Concurrent
+		g.FailOnError = t.FailOnError
+		g.MaxConcurrency = t.MaxConcurrency
+		g.Skip = skip
+		for i, sub := range t.Tasks {
+			r, err := sub.build(s)
+			if err != nil {
+				return nil, fmt.Errorf("tasks[%d]: %w", i, err)
+			}
+			g.AddTask(r)
+		}
+		return g, nil
+	}
+
+	// ...or a command
+	name, args := shellCommand(t.Run)
+	if len(t.Command) > 0 {
+		name, args = t.Command[0], t.Command[1:]
+	}
+	message := t.Message
+	if message == "" {
+		message = t.Run
+	}
+	if message == "" {
+		message = strings.Join(t.Command, " ")
+	}
+	ct := golist.NewCommandTask(message, name, args...)
+	ct.Env = s.env
+	ct.Dir = s.dir
+	ct.Skip = skip
+	return ct, nil
+}
+
+// skipFunc returns the task's Skip function,
+// or nil if it's never skipped
+func (t Task) skipFunc(s scope) func(golist.TaskContext) bool {
+	switch {
+	case t.Skip:
+		return func(golist.TaskContext) bool { return true }
+	case t.SkipIf != "":
+		return func(c golist.TaskContext) bool {
+			name, args := shellCommand(t.SkipIf)
+			cmd := exec.CommandContext(c.Context(), name, args...)
+			cmd.Env = append(os.Environ(), s.env...)
+			cmd.Dir = s.dir
+			return cmd.Run() == nil
+		}
+	}
+	return nil
+}
+
+// resolveDir resolves the directory `dir`
+// relative to the parent directory `parent`
+func resolveDir(parent, dir string) string {
+	if dir == "" {
+		return parent
+	}
+	if filepath.IsAbs(dir) || parent == "" {
+		return dir
+	}
+	return filepath.Join(parent, dir)
+}
+
+// shellCommand returns the command and arguments
+// for running `script` with the system's shell
+func shellCommand(script string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "cmd", []string{"/C", script}
+	}
+	return "sh", []string{"-c", script}
+}
@@ -0,0 +1,242 @@
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/a-poor/golist"
+)
+
+// skipWithoutShell skips tests that need a POSIX shell
+func skipWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs a POSIX shell")
+	}
+}
+
+func TestParse(t *testing.T) {
+	yml := []byte(`
+concurrent: true
+fail_on_error: true
+max_concurrency: 2
+env:
+  A: "1"
+tasks:
+  - message: Build
+    run: make build
+  - message: Checks
+    concurrent: true
+    tasks:
+      - command: [go, vet, ./...]
+        skip: true
+`)
+	json := []byte(`{
+		"concurrent": true,
+		"fail_on_error": true,
+		"max_concurrency": 2,
+		"env": {"A": "1"},
+		"tasks": [
+			{"message": "Build", "run": "make build"},
+			{"message": "Checks", "concurrent": true, "tasks": [
+				{"command": ["go", "vet", "./..."], "skip": true}
+			]}
+		]
+	}`)
+	expect := &File{
+		Concurrent:     true,
+		FailOnError:    true,
+		MaxConcurrency: 2,
+		Env:            map[string]string{"A": "1"},
+		Tasks: []Task{
+			{Message: "Build", Run: "make build"},
+			{Message: "Checks", Concurrent: true, Tasks: []Task{
+				{Command: []string{"go", "vet", "./..."}, Skip: true},
+			}},
+		},
+	}
+
+	for name, data := range map[string][]byte{"yaml": yml, "json": json} {
+		f, err := Parse(data)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if !reflect.DeepEqual(f, expect) {
+			t.Errorf("%s: expected %+v, got %+v", name, expect, f)
+		}
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	f, err := Parse(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(f.Tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(f.Tasks))
+	}
+}
+
+func TestParse_UnknownField(t *testing.T) {
+	if _, err := Parse([]byte("tasks:\n  - mesage: typo\n    run: echo hi\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestFile_List_InvalidTasks(t *testing.T) {
+	cases := map[string]struct {
+		tasks  []Task
+		expect error
+	}{
+		"no action": {
+			tasks:  []Task{{Message: "nothing"}},
+			expect: ErrNoAction,
+		},
+		"multiple actions": {
+			tasks:  []Task{{Run: "echo hi", Command: []string{"echo", "hi"}}},
+			expect: ErrMultipleActions,
+		},
+		"nested": {
+			tasks:  []Task{{Tasks: []Task{{Run: "echo hi"}, {}}}},
+			expect: ErrNoAction,
+		},
+	}
+	for name, c := range cases {
+		f := &File{Tasks: c.tasks}
+		if _, err := f.List(""); !errors.Is(err, c.expect) {
+			t.Errorf("%s: expected %v, got %v", name, c.expect, err)
+		}
+	}
+}
+
+func TestFile_List(t *testing.T) {
+	base := t.TempDir()
+	f := &File{
+		FailOnError: true,
+		Env:         map[string]string{"B": "2", "A": "1"},
+		Dir:         "src",
+		Tasks: []Task{
+			{Run: "echo hi"},
+			{Message: "Group", Concurrent: true, MaxConcurrency: 3, Dir: "sub", Env: map[string]string{"C": "3"}, Tasks: []Task{
+				{Message: "Abs", Command: []string{"ls"}, Dir: "/tmp"},
+			}},
+			{Command: []string{"go", "test", "./..."}},
+		},
+	}
+	l, err := f.List(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !l.FailOnError || l.Concurrent {
+		t.Errorf("expected the list's settings to be copied from the file")
+	}
+	if len(l.Tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(l.Tasks))
+	}
+
+	// A run task uses the shell and defaults its message to the script
+	ct, ok := l.Tasks[0].(*golist.CommandTask)
+	if !ok {
+		t.Fatalf("expected a *golist.CommandTask, got %T", l.Tasks[0])
+	}
+	if m := ct.GetMessage(); m != "echo hi" {
+		t.Errorf("expected message %q, got %q", "echo hi", m)
+	}
+	if d := filepath.Join(base, "src"); ct.Dir != d {
+		t.Errorf("expected dir %q, got %q", d, ct.Dir)
+	}
+	if e := []string{"A=1", "B=2"}; !reflect.DeepEqual(ct.Env, e) {
+		t.Errorf("expected env %q, got %q", e, ct.Env)
+	}
+
+	// Groups pass their settings, env, and dir down
+	g, ok := l.Tasks[1].(*golist.TaskGroup)
+	if !ok {
+		t.Fatalf("expected a *golist.TaskGroup, got %T", l.Tasks[1])
+	}
+	if !g.Concurrent || g.MaxConcurrency != 3 {
+		t.Errorf("expected the group's settings to be copied from the file")
+	}
+	sub := g.Tasks[0].(*golist.CommandTask)
+	if sub.Name != "ls" || len(sub.Args) != 0 {
+		t.Errorf("expected the command to be run directly, got %q %q", sub.Name, sub.Args)
+	}
+	if sub.Dir != "/tmp" {
+		t.Errorf("expected absolute dir %q, got %q", "/tmp", sub.Dir)
+	}
+	if e := []string{"A=1", "B=2", "C=3"}; !reflect.DeepEqual(sub.Env, e) {
+		t.Errorf("expected env %q, got %q", e, sub.Env)
+	}
+
+	// A command task defaults its message to the command line
+	if m := l.Tasks[2].(*golist.CommandTask).GetMessage(); m != "go test ./..." {
+		t.Errorf("expected message %q, got %q", "go test ./...", m)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "tasks.yaml")
+	data := []byte(`
+env:
+  NAME: world
+tasks:
+  - message: Check the directory
+    run: test -f marker
+  - message: Check the env
+    run: test "$NAME" = world
+  - message: Skipped
+    run: exit 1
+    skip_if: "true"
+  - message: Not skipped
+    run: "true"
+    skip_if: "false"
+`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var b bytes.Buffer
+	l.Writer = &b
+	l.Display = golist.DisplayLog
+	if err := l.RunAndWait(); err != nil {
+		t.Fatalf("unexpected error: %s\n%s", err, b.String())
+	}
+
+	expect := []golist.TaskStatus{golist.TaskCompleted, golist.TaskCompleted, golist.TaskSkipped, golist.TaskCompleted}
+	for i, r := range l.Tasks {
+		if s := r.GetStatus(); s != expect[i] {
+			t.Errorf("task %d: expected status %s, got %s", i, expect[i], s)
+		}
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	if err := os.WriteFile(path, []byte("tasks: [{message: nothing}]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); !errors.Is(err, ErrNoAction) {
+		t.Errorf("expected %v, got %v", ErrNoAction, err)
+	}
+}
@@ -0,0 +1,127 @@
+package golist
+
+import "time"
+
+// EventType represents the kind of thing an Event reports
+type EventType int
+
+const (
+	EventListStarted    EventType = iota // EventListStarted is sent when the List starts displaying
+	EventListStopped                     // EventListStopped is sent when the List stops displaying
+	EventTaskStarted                     // EventTaskStarted is sent when a task or task group starts running
+	EventMessageChanged                  // EventMessageChanged is sent when a task's message is set through its TaskContext
+	EventProgress                        // EventProgress is sent when a task reports its progress through its TaskContext
+	EventPrintln                         // EventPrintln is sent when a task prints a line through its TaskContext
+	EventTaskCompleted                   // EventTaskCompleted is sent when a task or task group completes successfully
+	EventTaskFailed                      // EventTaskFailed is sent when a task or task group fails, times out, or is cancelled
+	EventTaskSkipped                     // EventTaskSkipped is sent when a task or task group is skipped
+	EventTaskRetried                     // EventTaskRetried is sent when a task's attempt fails and it's going to be retried
+)
+
+// Format an EventType as a string
+func (t EventType) String() string {
+	switch t {
+	case EventListStarted:
+		return "ListStarted"
+	case EventListStopped:
+		return "ListStopped"
+	case EventTaskStarted:
+		return "TaskStarted"
+	case EventMessageChanged:
+		return "MessageChanged"
+	case EventProgress:
+		return "Progress"
+	case EventPrintln:
+		return "Println"
+	case EventTaskCompleted:
+		return "TaskCompleted"
+	case EventTaskFailed:
+		return "TaskFailed"
+	case EventTaskSkipped:
+		return "TaskSkipped"
+	case EventTaskRetried:
+		return "TaskRetried"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event describes something that happened during a List's run.
+// The task fields are left empty for the List's own events.
+type Event struct {
+	Type     EventType     // What happened
+	Time     time.Time     // When it happened
+	Task     TaskRunner    // The task it happened to
+	ID       string        // The task's ID, if it has one
+	Path     []string      // The task's path in the tree of tasks (see TaskError)
+	Status   TaskStatus    // The task's status
+	Message  string        // The task's message
+	Elapsed  time.Duration // How long the task has been running, or how long it ran for
+	Progress *Progress     // The task's progress, if it's reported any
+	Line     string        // For EventPrintln, the line that was printed
+	Attempt  int           // For EventTaskRetried, the number of the next attempt
+	Err      error         // For EventTaskFailed and EventTaskRetried, the task's error
+
+	parent TaskRunner // The TaskGroup the task belongs to, if any, since sibling groups' paths can be the same
+}
+
+// Observer is notified of the events during a List's run.
+// Observers are registered with `List.AddObserver`.
+//
+// The List sends events to its observers one at a time, in
+// order, from whichever goroutine the event happened on (or
+// from the one sending an earlier event), so observers should
+// return quickly. Observers can print lines with the List's
+// `Println`; the lines' events are sent once they return.
+type Observer interface {
+	OnEvent(e Event)
+}
+
+// ObserverFunc is a function that can be used as an Observer
+type ObserverFunc func(e Event)
+
+// OnEvent calls the ObserverFunc `f` with the event `e`
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+// newTaskEvent creates an event of type `typ` for the task
+// `t` (whose path is `path`), filled in with its current state
+func newTaskEvent(typ EventType, t TaskRunner, path []string) Event {
+	e := Event{
+		Type: typ,
+		Time: time.Now(),
+		Task: t,
+		ID:   getID(t),
+		Path: path,
+	}
+	if states := t.GetTaskStates(); len(states) > 0 {
+		s := states[0]
+		e.Status = s.Status
+		e.Message = s.Message
+		e.Elapsed = s.Elapsed
+		e.Progress = s.Progress
+	}
+	return e
+}
+
+// finishedEvent returns the type of event to send
+// when a task finishes with the status `s`
+func finishedEvent(s TaskStatus) EventType {
+	switch s {
+	case TaskCompleted:
+		return EventTaskCompleted
+	case TaskSkipped:
+		return EventTaskSkipped
+	default:
+		return EventTaskFailed
+	}
+}
+
+// skipTask marks the TaskRunner `t` as skipped without running
+// it, and tells the observers. `c` is the TaskContext that `t`
+// would have been run with.
+func skipTask(c TaskContext, t TaskRunner) {
+	t.SetStatus(TaskSkipped)
+	newChildContext(c, t).notify(EventTaskSkipped, t, nil)
+}
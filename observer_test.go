@@ -0,0 +1,185 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// eventLog is an Observer that records the events it's sent
+type eventLog []Event
+
+func (l *eventLog) OnEvent(e Event) {
+	*l = append(*l, e)
+}
+
+// String formats the events as "type path" lines
+func (l eventLog) String() string {
+	var b bytes.Buffer
+	for _, e := range l {
+		fmt.Fprintf(&b, "%s %s\n", e.Type, fmtPath(e.Path))
+	}
+	return b.String()
+}
+
+func TestList_Observers(t *testing.T) {
+	fail := errors.New("oops")
+	var attempts int
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.FailOnError = true
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		&Task{ID: "api", Message: "Deploy the API", Action: func(c TaskContext) error {
+			c.SetMessage("Deploying the API")
+			c.SetProgress(1, 2)
+			c.Println("hello world")
+			return nil
+		}},
+		&Task{
+			Message: "flaky",
+			Retry:   &RetryPolicy{MaxAttempts: 2, Delay: time.Millisecond},
+			Action: func(c TaskContext) error {
+				attempts++
+				return fail
+			},
+		},
+	}))
+	l.AddTask(NewTask("after", func(c TaskContext) error { return nil }))
+
+	var events eventLog
+	l.AddObserver(&events)
+	l.RunAndWait()
+
+	e := "ListStarted \n" +
+		"TaskStarted deploy\n" +
+		"TaskStarted deploy › api\n" +
+		"MessageChanged deploy › api\n" +
+		"Progress deploy › api\n" +
+		"Println deploy › api\n" +
+		"TaskCompleted deploy › api\n" +
+		"TaskStarted deploy › flaky\n" +
+		"TaskRetried deploy › flaky\n" +
+		"TaskFailed deploy › flaky\n" +
+		"TaskFailed deploy\n" +
+		"TaskSkipped after\n" +
+		"ListStopped \n"
+	if s := events.String(); s != e {
+		t.Fatalf("expected events:\n%s\ngot:\n%s", e, s)
+	}
+
+	if e := events[3]; e.Message != "Deploying the API" || e.ID != "api" {
+		t.Errorf("expected the new message and the task's ID, got %q and %q", e.Message, e.ID)
+	}
+	if e := events[4]; e.Progress == nil || e.Progress.Current != 1 || e.Progress.Total != 2 {
+		t.Errorf("expected progress of 1/2, got %+v", e.Progress)
+	}
+	if e := events[5]; e.Line != "hello world" {
+		t.Errorf("expected the printed line %q, got %q", "hello world", e.Line)
+	}
+	if e := events[6]; e.Status != TaskCompleted || e.Elapsed <= 0 || e.Time.IsZero() {
+		t.Errorf("expected a completed status, duration, and time, got %+v", e)
+	}
+	if e := events[8]; e.Attempt != 2 || e.Err != fail {
+		t.Errorf("expected a retry for attempt 2 after %v, got attempt %d after %v", fail, e.Attempt, e.Err)
+	}
+	if e := events[9]; e.Status != TaskFailed || e.Err != fail {
+		t.Errorf("expected a failure with %v, got %s with %v", fail, e.Status, e.Err)
+	}
+}
+
+func TestList_ObserverFunc(t *testing.T) {
+	var n int
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.AddObserver(ObserverFunc(func(e Event) {
+		if e.Type == EventTaskCompleted {
+			n++
+		}
+	}))
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return nil }))
+	l.AddTask(NewTask("t1", func(c TaskContext) error { return nil }))
+	l.RunAndWait()
+
+	if n != 2 {
+		t.Errorf("expected 2 completed events, got %d", n)
+	}
+}
+
+func TestList_ObserversConcurrent(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.Concurrent = true
+
+	// The observer isn't safe for concurrent use, so this
+	// checks that events are sent one at a time
+	var events eventLog
+	l.AddObserver(&events)
+	for i := 0; i < 20; i++ {
+		l.AddTask(NewTask(fmt.Sprint(i), func(c TaskContext) error {
+			c.SetMessage("running")
+			return nil
+		}))
+	}
+	l.RunAndWait()
+
+	if len(events) != 2+20*3 {
+		t.Errorf("expected %d events, got %d", 2+20*3, len(events))
+	}
+}
+
+func TestList_ObserverPrints(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.Display = DisplayJSON
+
+	// The observer prints a line when a task fails, and
+	// gets the line's event once it's returned
+	var events eventLog
+	l.AddObserver(ObserverFunc(func(e Event) {
+		events.OnEvent(e)
+		if e.Type == EventTaskFailed {
+			l.Println("failed: " + e.Message)
+		}
+	}))
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return errors.New("oops") }))
+
+	done := make(chan struct{})
+	go func() {
+		l.RunAndWait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected printing from an observer not to deadlock")
+	}
+
+	var types []EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	expect := []EventType{EventListStarted, EventTaskStarted, EventTaskFailed, EventPrintln, EventListStopped}
+	if fmt.Sprint(types) != fmt.Sprint(expect) {
+		t.Fatalf("expected events %v, got %v", expect, types)
+	}
+	if line := events[3].Line; line != "failed: t0" {
+		t.Errorf("expected the printed line, got %q", line)
+	}
+}
+
+func TestFinishedEvent(t *testing.T) {
+	cases := map[TaskStatus]EventType{
+		TaskCompleted: EventTaskCompleted,
+		TaskSkipped:   EventTaskSkipped,
+		TaskFailed:    EventTaskFailed,
+		TaskTimedOut:  EventTaskFailed,
+		TaskCancelled: EventTaskFailed,
+	}
+	for s, e := range cases {
+		if typ := finishedEvent(s); typ != e {
+			t.Errorf("expected %s for %s, got %s", e, s, typ)
+		}
+	}
+}
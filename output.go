@@ -0,0 +1,117 @@
+package golist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskOutputMode represents what a List does with the lines its
+// tasks print (through `TaskContext.Println` and `Printfln`).
+//
+// Whatever the mode, each task keeps the last lines it printed
+// (see `List.OutputBuffer`). If the list isn't redrawn in place
+// (e.g. with DisplayLog), there's nowhere to show the last lines
+// below the tasks, so OutputTail prints them straight away, like
+// OutputPassthrough.
+type TaskOutputMode int
+
+const (
+	OutputPassthrough TaskOutputMode = iota // OutputPassthrough prints each line above the list as soon as it's printed
+	OutputTail                              // OutputTail shows the last few lines (see `List.TailLines`) below each running task, and prints a task's lines above the list if it fails
+	OutputOnFailure                         // OutputOnFailure keeps each task's lines and only prints them, together, above the list if the task fails
+)
+
+// DefaultTailLines is the default number of lines
+// shown below each running task, with OutputTail
+const DefaultTailLines = 5
+
+// DefaultOutputBufferLines is the default number
+// of printed lines that each task keeps
+const DefaultOutputBufferLines = 1000
+
+// ringBuffer keeps the last lines added to it, up to its
+// size, dropping the oldest ones. If its size is 0 (or
+// negative), it keeps all of them.
+//
+// A ringBuffer isn't safe for concurrent use.
+type ringBuffer struct {
+	size    int      // The maximum number of lines to keep
+	lines   []string // The lines kept
+	start   int      // The index of the oldest line, once the buffer is full
+	dropped int      // The number of lines dropped to make space
+}
+
+// newRingBuffer creates a ringBuffer that keeps `size` lines
+func newRingBuffer(size int) ringBuffer {
+	return ringBuffer{size: size}
+}
+
+// add adds the line `s`, dropping the oldest line if it's full
+func (b *ringBuffer) add(s string) {
+	if b.size <= 0 || len(b.lines) < b.size {
+		b.lines = append(b.lines, s)
+		return
+	}
+	b.lines[b.start] = s
+	b.start = (b.start + 1) % len(b.lines)
+	b.dropped++
+}
+
+// get returns the lines kept, oldest first
+func (b *ringBuffer) get() []string {
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.start:]...)
+	return append(lines, b.lines[:b.start]...)
+}
+
+// fmtOutput formats the lines printed by the task at `path` to
+// be printed together, with a header saying which task they're
+// from and how many earlier lines were dropped, if any.
+func fmtOutput(path []string, lines []string, dropped int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Output from %s:", fmtPath(path))
+	if dropped > 0 {
+		fmt.Fprintf(&b, "\n  … %d earlier lines", dropped)
+	}
+	for _, line := range lines {
+		b.WriteString("\n  " + line)
+	}
+	return b.String()
+}
+
+// outputRows returns rows showing the printed `lines` of the
+// task with the state `s`, to be drawn below it
+func outputRows(s *TaskState, lines []string) []*TaskState {
+	rows := make([]*TaskState, len(lines))
+	for i, line := range lines {
+		rows[i] = &TaskState{
+			Message: line,
+			Status:  s.Status,
+			Depth:   s.Depth + 1,
+			logLine: true,
+		}
+	}
+	return rows
+}
+
+// withTails returns the task states with the last `n`
+// lines printed by each running task added below it
+func withTails(states []*TaskState, n int) []*TaskState {
+	if n <= 0 {
+		return states
+	}
+	var tailed []*TaskState
+	for _, s := range states {
+		tailed = append(tailed, s)
+		o, ok := s.runner.(outputter)
+		if !ok || s.Status != TaskInProgress {
+			continue
+		}
+		lines := o.getOutput()
+		if len(lines) > n {
+			lines = lines[len(lines)-n:]
+		}
+		tailed = append(tailed, outputRows(s, lines)...)
+	}
+	return tailed
+}
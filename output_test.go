@@ -0,0 +1,203 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	b := newRingBuffer(3)
+	if lines := b.get(); len(lines) != 0 {
+		t.Errorf("expected no lines, got %q", lines)
+	}
+	for _, s := range []string{"a", "b"} {
+		b.add(s)
+	}
+	if lines := b.get(); !reflect.DeepEqual(lines, []string{"a", "b"}) {
+		t.Errorf("expected %q, got %q", []string{"a", "b"}, lines)
+	}
+	for _, s := range []string{"c", "d", "e"} {
+		b.add(s)
+	}
+	if lines := b.get(); !reflect.DeepEqual(lines, []string{"c", "d", "e"}) {
+		t.Errorf("expected %q, got %q", []string{"c", "d", "e"}, lines)
+	}
+	if b.dropped != 2 {
+		t.Errorf("expected 2 dropped lines, got %d", b.dropped)
+	}
+
+	// A size of 0 keeps everything
+	b = newRingBuffer(0)
+	for i := 0; i < 100; i++ {
+		b.add("x")
+	}
+	if n := len(b.get()); n != 100 || b.dropped != 0 {
+		t.Errorf("expected all 100 lines to be kept, got %d", n)
+	}
+}
+
+func TestFmtOutput(t *testing.T) {
+	s := fmtOutput([]string{"build", "test"}, []string{"a", "b"}, 0)
+	if e := "Output from build › test:\n  a\n  b"; s != e {
+		t.Errorf("expected %q, got %q", e, s)
+	}
+	s = fmtOutput([]string{"test"}, []string{"b"}, 3)
+	if e := "Output from test:\n  … 3 earlier lines\n  b"; s != e {
+		t.Errorf("expected %q, got %q", e, s)
+	}
+}
+
+func TestWithTails(t *testing.T) {
+	running, done := NewTask("running", nil), NewTask("done", nil)
+	for _, task := range []*Task{running, done} {
+		for _, s := range []string{"1", "2", "3"} {
+			task.addOutput(s)
+		}
+	}
+	running.SetStatus(TaskInProgress)
+	done.SetStatus(TaskCompleted)
+	states := append(running.GetTaskStates(), done.GetTaskStates()...)
+
+	var msgs []string
+	for _, s := range withTails(states, 2) {
+		msgs = append(msgs, strings.Repeat(" ", s.Depth)+s.Message)
+	}
+	if e := []string{"running", " 2", " 3", "done"}; !reflect.DeepEqual(msgs, e) {
+		t.Errorf("expected %q, got %q", e, msgs)
+	}
+	if tailed := withTails(states, 0); len(tailed) != len(states) {
+		t.Errorf("expected no tails with 0 lines, got %d rows", len(tailed))
+	}
+}
+
+// runOutputList runs a List with the TaskOutputMode `mode`
+// and a successful and failing task that each print two
+// lines, and returns what it wrote
+func runOutputList(mode TaskOutputMode, display DisplayMode) string {
+	var b bytes.Buffer
+	l := NewListWithWriter(&b)
+	l.Display = display
+	l.TaskOutput = mode
+	l.AddTask(NewTask("pass", func(c TaskContext) error {
+		c.Println("pass 1")
+		c.Printfln("pass %d", 2)
+		return nil
+	}))
+	l.AddTask(NewTask("fail", func(c TaskContext) error {
+		c.Println("fail 1")
+		c.Printfln("fail %d", 2)
+		return errors.New("oops")
+	}))
+	l.RunAndWait()
+	return b.String()
+}
+
+func TestList_TaskOutput(t *testing.T) {
+	// Lines are printed straight away by default
+	out := runOutputList(OutputPassthrough, DisplayLog)
+	for _, line := range []string{"pass 1", "pass 2", "fail 1", "fail 2"} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected %q to be printed, got %q", line, out)
+		}
+	}
+	if strings.Contains(out, "Output from") {
+		t.Errorf("expected the output not to be printed again, got %q", out)
+	}
+
+	// Only the failed task's lines are printed, together
+	out = runOutputList(OutputOnFailure, DisplayLog)
+	if strings.Contains(out, "pass 1") {
+		t.Errorf("expected the successful task's lines to be held back, got %q", out)
+	}
+	if e := "Output from fail:\n  fail 1\n  fail 2\n"; !strings.Contains(out, e) {
+		t.Errorf("expected %q to be printed, got %q", e, out)
+	}
+
+	// Without a terminal, tails are printed straight away
+	out = runOutputList(OutputTail, DisplayLog)
+	if !strings.Contains(out, "pass 1\n") || strings.Contains(out, "Output from") {
+		t.Errorf("expected the lines to be printed straight away, got %q", out)
+	}
+
+	// With a terminal, they're only printed if the task fails
+	out = runOutputList(OutputTail, DisplayTerminal)
+	if strings.Contains(out, "pass 1") {
+		t.Errorf("expected the successful task's lines to be held back, got %q", out)
+	}
+	if !strings.Contains(out, "Output from fail:") {
+		t.Errorf("expected the failed task's lines to be printed, got %q", out)
+	}
+}
+
+func TestList_OutputBuffer(t *testing.T) {
+	var b bytes.Buffer
+	l := NewListWithWriter(&b)
+	l.Display = DisplayLog
+	l.TaskOutput = OutputOnFailure
+	l.OutputBuffer = 2
+	task := NewTask("fail", func(c TaskContext) error {
+		for _, s := range []string{"a", "b", "c", "d"} {
+			c.Println(s)
+		}
+		return errors.New("oops")
+	})
+	l.AddTask(task)
+	l.RunAndWait()
+
+	if out := task.getOutput(); !reflect.DeepEqual(out, []string{"c", "d"}) {
+		t.Errorf("expected the last 2 lines to be kept, got %q", out)
+	}
+	if e := "Output from fail:\n  … 2 earlier lines\n  c\n  d\n"; !strings.Contains(b.String(), e) {
+		t.Errorf("expected %q to be printed, got %q", e, b.String())
+	}
+}
+
+func TestTask_OutputBufferLimit(t *testing.T) {
+	task := NewTask("chatty", func(c TaskContext) error {
+		for i := 0; i < DefaultOutputBufferLines+10; i++ {
+			c.Println(i)
+		}
+		return nil
+	})
+
+	// A zero buffer (as in a zero-value List) keeps
+	// the default number of lines...
+	discard := func(...interface{}) error { return nil }
+	task.Run(&taskContext{println: discard})
+	if n := len(task.getOutput()); n != DefaultOutputBufferLines {
+		t.Errorf("expected %d lines to be kept, got %d", DefaultOutputBufferLines, n)
+	}
+
+	// ...and a negative buffer keeps all of them
+	task.Run(&taskContext{println: discard, outputLines: -1})
+	if n := len(task.getOutput()); n != DefaultOutputBufferLines+10 {
+		t.Errorf("expected %d lines to be kept, got %d", DefaultOutputBufferLines+10, n)
+	}
+}
+
+func TestTerminalRenderer_Tails(t *testing.T) {
+	r, s := newTestRenderer()
+	r.l.TaskOutput = OutputTail
+	r.l.TailLines = 2
+	task := NewTask("t", nil)
+	r.l.AddTask(task)
+	task.SetStatus(TaskInProgress)
+	for _, line := range []string{"one", "two", "three"} {
+		task.addOutput(line)
+	}
+
+	r.render(r.l.getTaskStates())
+	if e := "– t\n  │ two\n  │ three"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	// The tail is hidden once the task finishes
+	task.SetStatus(TaskCompleted)
+	r.render(r.l.getTaskStates())
+	if e := "– t"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
@@ -0,0 +1,76 @@
+package golist
+
+import (
+	"context"
+	"sync"
+)
+
+// gate holds tasks back from starting while it's paused.
+// A nil *gate is never paused.
+type gate struct {
+	mu     sync.Mutex
+	resume chan struct{} // Closed when the gate is resumed (nil if it isn't paused)
+}
+
+// pause closes the gate, so tasks wait before starting
+func (g *gate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resume == nil {
+		g.resume = make(chan struct{})
+	}
+}
+
+// unpause opens the gate, letting any waiting tasks start
+func (g *gate) unpause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resume != nil {
+		close(g.resume)
+		g.resume = nil
+	}
+}
+
+// paused returns true if the gate is paused
+func (g *gate) paused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resume != nil
+}
+
+// wait blocks while the gate is paused, or until `ctx` is done
+func (g *gate) wait(ctx context.Context) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	resume := g.resume
+	g.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+}
+
+// Pause stops the List from starting any more tasks until
+// `Resume` is called. Tasks that are already running carry
+// on, and cancelling the run still skips the waiting tasks.
+func (l *List) Pause() {
+	l.pause.pause()
+}
+
+// Resume lets a paused List start running tasks again
+func (l *List) Resume() {
+	l.pause.unpause()
+}
+
+// Paused returns true if the List is paused
+func (l *List) Paused() bool {
+	return l.pause.paused()
+}
@@ -0,0 +1,98 @@
+package golist
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	var g *gate
+	if g.paused() {
+		t.Error("expected a nil gate to never be paused")
+	}
+	g.wait(context.Background())
+
+	g = &gate{}
+	g.pause()
+	g.pause()
+	if !g.paused() {
+		t.Error("expected the gate to be paused")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.wait(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected wait to block while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.unpause()
+	g.unpause()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected wait to return once resumed")
+	}
+
+	// Waiting stops when the context is done
+	g.pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.wait(ctx)
+}
+
+func TestList_Pause(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Display = DisplayLog
+	task := NewTask("t", func(c TaskContext) error { return nil })
+	l.AddTask(task)
+
+	l.Pause()
+	done := make(chan error, 1)
+	go func() { done <- l.RunAndWait() }()
+
+	time.Sleep(50 * time.Millisecond)
+	if s := task.GetStatus(); s != TaskNotStarted {
+		t.Errorf("expected the task to wait while paused, got %s", s)
+	}
+
+	l.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the run to finish once resumed")
+	}
+	if s := task.GetStatus(); s != TaskCompleted {
+		t.Errorf("expected status %s, got %s", TaskCompleted, s)
+	}
+}
+
+func TestList_PauseCancel(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Display = DisplayLog
+	l.Concurrent = true
+	for i := 0; i < 3; i++ {
+		l.AddTask(NewTask("t", func(c TaskContext) error { return nil }))
+	}
+
+	l.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.RunAndWaitContext(ctx); err == nil {
+		t.Error("expected the run to be cancelled")
+	}
+	for _, task := range l.Tasks {
+		if s := task.GetStatus(); s != TaskSkipped {
+			t.Errorf("expected waiting tasks to be skipped, got %s", s)
+		}
+	}
+}
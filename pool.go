@@ -0,0 +1,84 @@
+package golist
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// workerPool runs functions on a limited number of goroutines.
+// Functions submitted while every worker is busy are queued and
+// run in the order they were submitted.
+//
+// A nil *workerPool has no limit and runs each submitted
+// function on its own goroutine.
+type workerPool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []func()
+	closed bool
+	queued *int32 // Number of queued functions, for displaying
+}
+
+// newWorkerPool creates a workerPool with `n` workers that
+// keeps the number of queued functions in `queued`, which may
+// be nil. If `n` is 0 (or negative), it returns a nil pool.
+//
+// The pool's workers exit after `close` is called and
+// the queue is empty.
+func newWorkerPool(n int, queued *int32) *workerPool {
+	if n <= 0 {
+		return nil
+	}
+	if queued == nil {
+		queued = new(int32)
+	}
+	p := &workerPool{queued: queued}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// work runs queued functions until the pool is closed
+func (p *workerPool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		f := p.queue[0]
+		p.queue = p.queue[1:]
+		atomic.AddInt32(p.queued, -1)
+		p.mu.Unlock()
+		f()
+	}
+}
+
+// submit queues `f` to be run by the next free worker
+func (p *workerPool) submit(f func()) {
+	if p == nil {
+		go f()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queue = append(p.queue, f)
+	atomic.AddInt32(p.queued, 1)
+	p.cond.Signal()
+}
+
+// close tells the workers to exit once the queue is empty
+func (p *workerPool) close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.cond.Broadcast()
+}
@@ -0,0 +1,82 @@
+package golist
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_Nil(t *testing.T) {
+	p := newWorkerPool(0, nil)
+	if p != nil {
+		t.Fatal("expected a nil pool when there's no limit")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p.submit(wg.Done)
+	wg.Wait()
+	p.close()
+}
+
+func TestWorkerPool_Limit(t *testing.T) {
+	var queued int32
+	p := newWorkerPool(2, &queued)
+	defer p.close()
+
+	var running, maxRunning int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		p.submit(func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+		})
+	}
+
+	// Wait for the first two functions to be picked up
+	for atomic.LoadInt32(&queued) != 4 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if m := atomic.LoadInt32(&maxRunning); m > 2 {
+		t.Errorf("expected at most 2 functions running at once, got %d", m)
+	}
+	if q := atomic.LoadInt32(&queued); q != 0 {
+		t.Errorf("expected nothing queued, got %d", q)
+	}
+}
+
+func TestWorkerPool_Order(t *testing.T) {
+	p := newWorkerPool(1, nil)
+	defer p.close()
+
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		i := i
+		wg.Add(1)
+		p.submit(func() {
+			defer wg.Done()
+			order = append(order, i)
+		})
+	}
+	wg.Wait()
+	for i, n := range order {
+		if i != n {
+			t.Fatalf("expected functions to run in order, got %v", order)
+		}
+	}
+}
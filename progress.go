@@ -0,0 +1,122 @@
+package golist
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ProgressBarWidth is the width of a task's progress bar, in cells
+const ProgressBarWidth = 20
+
+// Progress describes how far through its work a task is,
+// as reported with TaskContext.SetProgress
+type Progress struct {
+	Current int64         // The amount of work done so far
+	Total   int64         // The total amount of work to do (0 if unknown)
+	Rate    float64       // The amount of work done per second (0 if unknown)
+	ETA     time.Duration // The estimated time left until the work is done (0 if unknown)
+}
+
+// Indeterminate returns true if the total amount of work isn't known
+func (p *Progress) Indeterminate() bool {
+	return p.Total <= 0
+}
+
+// Fraction returns the fraction of the work that's done,
+// between 0 and 1, or 0 if the progress is indeterminate
+func (p *Progress) Fraction() float64 {
+	if p.Indeterminate() {
+		return 0
+	}
+	f := float64(p.Current) / float64(p.Total)
+	return math.Max(0, math.Min(1, f))
+}
+
+// progressTracker records a task's progress reports
+// to work out its rate and ETA
+type progressTracker struct {
+	set          bool      // Has any progress been reported?
+	current      int64     // The latest amount of work done
+	total        int64     // The latest total amount of work (0 if unknown)
+	firstAt      time.Time // When the first progress report was made
+	firstCurrent int64     // The amount of work done at the first report
+}
+
+// update records a progress report
+func (p *progressTracker) update(current, total int64) {
+	if !p.set {
+		p.set = true
+		p.firstAt = time.Now()
+		p.firstCurrent = current
+	}
+	p.current, p.total = current, total
+}
+
+// get returns the task's Progress, or nil if
+// no progress has been reported
+func (p *progressTracker) get() *Progress {
+	if !p.set {
+		return nil
+	}
+	prog := &Progress{
+		Current: p.current,
+		Total:   p.total,
+	}
+
+	// The rate is measured from the first report, once
+	// there's been long enough to make a fair guess
+	if d := time.Since(p.firstAt); d >= time.Second {
+		prog.Rate = float64(p.current-p.firstCurrent) / d.Seconds()
+	}
+	if prog.Rate > 0 && !prog.Indeterminate() && p.current < p.total {
+		secs := float64(p.total-p.current) / prog.Rate
+		prog.ETA = time.Duration(secs * float64(time.Second))
+	}
+	return prog
+}
+
+// fmtProgress formats a running task's progress (e.g.
+// "[████░░░░] 42% 3.1/s ETA 12s"), or returns an empty string
+// if it hasn't reported any progress or isn't running.
+//
+// Indeterminate progress is shown as the amount of work
+// done and the rate (e.g. "1234 3.1/s").
+func fmtProgress(m *TaskState) string {
+	p := m.Progress
+	if p == nil || m.Status != TaskInProgress {
+		return ""
+	}
+
+	var parts []string
+	if p.Indeterminate() {
+		parts = append(parts, fmt.Sprint(p.Current))
+	} else {
+		f := p.Fraction()
+		full := int(f * ProgressBarWidth)
+		bar := strings.Repeat("█", full) + strings.Repeat("░", ProgressBarWidth-full)
+		parts = append(parts, "["+bar+"]", fmt.Sprintf("%d%%", int(f*100)))
+	}
+	if p.Rate > 0 {
+		parts = append(parts, fmtRate(p.Rate))
+	}
+	if p.ETA > 0 {
+		// Round up, so the ETA doesn't say 0s early
+		eta := (p.ETA + time.Second - 1).Truncate(time.Second)
+		parts = append(parts, "ETA "+eta.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// fmtRate formats a rate of work per second, with
+// a metric suffix for large rates (e.g. "3.1k/s")
+func fmtRate(r float64) string {
+	suffixes := []string{"", "k", "M", "G", "T"}
+	i := 0
+	for r >= 1000 && i < len(suffixes)-1 {
+		r /= 1000
+		i++
+	}
+	return fmt.Sprintf("%.1f%s/s", r, suffixes[i])
+}
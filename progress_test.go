@@ -0,0 +1,110 @@
+package golist
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress_Fraction(t *testing.T) {
+	cases := []struct {
+		p Progress
+		e float64
+	}{
+		{Progress{Current: 5, Total: 10}, 0.5},
+		{Progress{Current: 20, Total: 10}, 1},
+		{Progress{Current: -1, Total: 10}, 0},
+		{Progress{Current: 5}, 0},
+	}
+	for _, c := range cases {
+		if f := c.p.Fraction(); f != c.e {
+			t.Errorf("expected %+v to be %v done, got %v", c.p, c.e, f)
+		}
+	}
+	if p := (Progress{Current: 5}); !p.Indeterminate() {
+		t.Error("expected progress without a total to be indeterminate")
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	var p progressTracker
+	if p.get() != nil {
+		t.Error("expected no progress before any reports")
+	}
+
+	p.update(10, 100)
+	prog := p.get()
+	if prog.Current != 10 || prog.Total != 100 {
+		t.Errorf("expected 10/100, got %d/%d", prog.Current, prog.Total)
+	}
+	if prog.Rate != 0 || prog.ETA != 0 {
+		t.Errorf("expected no rate or ETA straight away, got %v and %s", prog.Rate, prog.ETA)
+	}
+
+	// Pretend the first report was made 2 seconds ago
+	p.firstAt = time.Now().Add(-2 * time.Second)
+	p.update(50, 100)
+	prog = p.get()
+	if prog.Rate < 19 || prog.Rate > 20 {
+		t.Errorf("expected a rate of about 20/s, got %v", prog.Rate)
+	}
+	if prog.ETA < 2*time.Second || prog.ETA > 3*time.Second {
+		t.Errorf("expected an ETA of about 2.5s, got %s", prog.ETA)
+	}
+}
+
+func TestFmtProgress(t *testing.T) {
+	cases := []struct {
+		s *TaskState
+		e string
+	}{
+		{
+			&TaskState{Status: TaskInProgress, Progress: &Progress{Current: 1, Total: 4}},
+			"[█████░░░░░░░░░░░░░░░] 25%",
+		},
+		{
+			&TaskState{Status: TaskInProgress, Progress: &Progress{Current: 1, Total: 4, Rate: 3.14, ETA: 1500 * time.Millisecond}},
+			"[█████░░░░░░░░░░░░░░░] 25% 3.1/s ETA 2s",
+		},
+		{
+			&TaskState{Status: TaskInProgress, Progress: &Progress{Current: 1234, Rate: 56700}},
+			"1234 56.7k/s",
+		},
+		{
+			&TaskState{Status: TaskCompleted, Progress: &Progress{Current: 4, Total: 4}},
+			"",
+		},
+		{
+			&TaskState{Status: TaskInProgress},
+			"",
+		},
+	}
+	for _, c := range cases {
+		if p := fmtProgress(c.s); p != c.e {
+			t.Errorf("expected %q, got %q", c.e, p)
+		}
+	}
+}
+
+func TestFmtRate(t *testing.T) {
+	cases := map[float64]string{
+		0.5:     "0.5/s",
+		999:     "999.0/s",
+		1500:    "1.5k/s",
+		2500000: "2.5M/s",
+	}
+	for r, e := range cases {
+		if s := fmtRate(r); s != e {
+			t.Errorf("expected %v to format as %q, got %q", r, e, s)
+		}
+	}
+}
+
+func TestList_FormatProgress(t *testing.T) {
+	l := NewList()
+	l.StatusIndicator = StatusIndicators{}
+	s := &TaskState{Message: "download", Status: TaskInProgress, Progress: &Progress{Current: 1, Total: 2}}
+	if m := l.formatMessage(s); !strings.HasPrefix(m, "– download [") || !strings.HasSuffix(m, "] 50%") {
+		t.Errorf("expected the message to be followed by a progress bar, got %q", m)
+	}
+}
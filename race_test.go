@@ -0,0 +1,160 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// These tests hammer the task state from multiple goroutines
+// at once. They're most useful when run with `go test -race`.
+
+const raceIterations = 200
+
+func TestRace_Task(t *testing.T) {
+	k := NewTask("test", func(c TaskContext) error { return nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				k.SetMessage(fmt.Sprintf("message %d-%d", i, j))
+				k.SetStatus(TaskStatus(j % 5))
+				k.SetError(errors.New("oops"))
+				k.setAttempt(j, time.Time{})
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				k.GetMessage()
+				k.GetStatus()
+				k.GetError()
+				k.GetTaskStates()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRace_TaskGroup(t *testing.T) {
+	g := NewTaskGroup("test", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error { return nil }),
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				g.SetMessage(fmt.Sprintf("message %d-%d", i, j))
+				g.SetStatus(TaskStatus(j % 5))
+				g.setError(errors.New("oops"))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				g.GetMessage()
+				g.GetStatus()
+				g.GetError()
+				g.GetTaskStates()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRace_CycleIndicator(t *testing.T) {
+	si := CreateDefaultStatusIndicator()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				si.Next()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < raceIterations; j++ {
+				si.Get(TaskInProgress)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRace_ListRun(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Delay = time.Millisecond
+	l.Concurrent = true
+
+	busy := func(c TaskContext) error {
+		for j := 0; j < 20; j++ {
+			c.SetMessage(fmt.Sprintf("step %d", j))
+			c.Println("working on step", j)
+			time.Sleep(time.Millisecond / 2)
+		}
+		return nil
+	}
+	for i := 0; i < 4; i++ {
+		l.AddTask(&TaskGroup{
+			Message:        fmt.Sprintf("group %d", i),
+			Concurrent:     true,
+			MaxConcurrency: 2,
+			Tasks: []TaskRunner{
+				NewTask("t0", busy),
+				NewTask("t1", busy),
+				&Task{
+					Message: "t2",
+					Timeout: time.Millisecond * 5,
+					Action: func(c TaskContext) error {
+						<-c.Context().Done()
+						return c.Context().Err()
+					},
+				},
+				NewTask("t3", func(c TaskContext) error {
+					return errors.New("oh no")
+				}),
+			},
+		})
+	}
+
+	if err := l.RunAndWait(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRace_ListStartStop(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Delay = time.Millisecond
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.Start()
+				l.Stop()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.Println("hello")
+			}
+		}()
+	}
+	wg.Wait()
+	l.Stop()
+}
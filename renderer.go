@@ -0,0 +1,403 @@
+package golist
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// DisplayMode represents how a List draws its task states
+type DisplayMode int
+
+const (
+	DisplayAuto     DisplayMode = iota // DisplayAuto uses DisplayTerminal if the List's Writer is a terminal, otherwise DisplayLog
+	DisplayTerminal                    // DisplayTerminal redraws the list in place using ANSI escape characters
+	DisplayLog                         // DisplayLog prints a plain line each time a task's status changes
+	DisplayJSON                        // DisplayJSON writes each of the List's events (see `Event`) as a line of JSON
+	DisplayTAP                         // DisplayTAP writes the results as TAP (Test Anything Protocol) version 14, with task groups as subtests
+)
+
+const (
+	ansiHideCursor = "\033[?25l" // Hide the cursor
+	ansiShowCursor = "\033[?25h" // Show the cursor
+	ansiClearLine  = "\033[K"    // Clear from the cursor to the end of the line
+	ansiClearDown  = "\033[J"    // Clear from the cursor to the end of the screen
+	ansiReverse    = "\033[7m"   // Swap the text and background colors
+	ansiNoReverse  = "\033[27m"  // Stop swapping the text and background colors
+)
+
+// renderer draws a List's task states to its Writer.
+//
+// A renderer's methods are only called from
+// the List's display goroutine.
+type renderer interface {
+	render(states []*TaskState)             // Draw the current task states
+	println(s string)                       // Print a line of text above the task states
+	finish(states []*TaskState, clear bool) // Draw the final task states, or clear them if `clear` is true
+	resize()                                // Called when the terminal is resized
+}
+
+// newRenderer creates the renderer for the List's DisplayMode
+func (l *List) newRenderer() renderer {
+	switch l.Display {
+	case DisplayTerminal:
+		return newTerminalRenderer(l, l.Writer)
+	case DisplayLog:
+		return newLogRenderer(l, l.Writer)
+	case DisplayJSON:
+		return newJSONRenderer(l.Writer)
+	case DisplayTAP:
+		return newTAPRenderer(l.Writer)
+	}
+	if isTerminal(l.Writer) {
+		return newTerminalRenderer(l, l.Writer)
+	}
+	return newLogRenderer(l, l.Writer)
+}
+
+// isTerminal returns true if `w` is a file
+// (like os.Stdout) that's connected to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// termSize returns the width and height of the terminal that
+// `w` is connected to, or false if it isn't a terminal.
+func termSize(w io.Writer) (width, height int, ok bool) {
+	f, isFile := w.(interface{ Fd() uintptr })
+	if !isFile {
+		return 0, 0, false
+	}
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// ansiEscape matches ANSI escape sequences
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;?]*[a-zA-Z]")
+
+// stripANSI removes any ANSI escape sequences
+// (like colors) from the string `s`
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// terminalRenderer is a renderer that draws the task
+// states in place, using ANSI escape characters.
+//
+// It keeps track of the last frame it drew and only
+// redraws the lines that have changed since then.
+//
+// If the list is taller than the terminal (or the List's
+// MaxHeight), it's fit into a viewport with `fitHeight`.
+//
+// If the List is interactive, its controls are applied to the
+// task states before they're drawn, the selected task is
+// highlighted, and the keys are described below the list.
+type terminalRenderer struct {
+	l        *List     // The list, for formatting task states
+	w        io.Writer // Where to draw
+	frame    []string  // The lines drawn in the last frame
+	width    int       // The terminal's width (0 if unknown)
+	height   int       // The terminal's height (0 if unknown)
+	controls *controls // The keyboard controls, if the List is interactive (nil otherwise)
+	raw      bool      // Is the terminal in raw mode? If so, newlines are written as "\r\n"
+}
+
+// newTerminalRenderer creates a terminalRenderer
+// that draws the List `l` to `w`.
+func newTerminalRenderer(l *List, w io.Writer) *terminalRenderer {
+	r := &terminalRenderer{
+		l: l,
+		w: w,
+	}
+	r.width, r.height, _ = termSize(w)
+	return r
+}
+
+// write writes `s` to the terminal. In raw mode, the terminal
+// doesn't return to the start of the line on "\n", so it's
+// written as "\r\n".
+func (r *terminalRenderer) write(s string) {
+	if r.raw {
+		s = strings.ReplaceAll(s, "\n", "\r\n")
+	}
+	fmt.Fprint(r.w, s)
+}
+
+// maxLines returns the maximum number of lines to draw
+// (or 0 for no limit). By default, one line is left
+// free for the cursor, below the list.
+func (r *terminalRenderer) maxLines() int {
+	if r.l.MaxHeight > 0 {
+		return r.l.MaxHeight
+	}
+	if r.height > 1 {
+		return r.height - 1
+	}
+	return 0
+}
+
+// render draws the task states, only moving the cursor to (and
+// rewriting) the lines that changed since the last frame. Nothing
+// is written if the frame hasn't changed.
+func (r *terminalRenderer) render(states []*TaskState) {
+	lines := r.fmtFrame(states)
+	s := r.fmtDiff(lines)
+	r.frame = lines
+	if s == "" {
+		return
+	}
+	r.write(ansiHideCursor + s + ansiShowCursor)
+}
+
+// lineWidth returns the maximum width of a line,
+// in terminal cells (or 0 for no limit)
+func (r *terminalRenderer) lineWidth() int {
+	if r.l.MaxLineLength == AutoLineLength {
+		return r.width
+	}
+	return r.l.MaxLineLength
+}
+
+// fmtFrame formats the task states into the rows of a frame.
+// Lines that are wider than the terminal are split into the
+// rows the terminal would wrap them onto, so that each line
+// of the frame is exactly one row on the screen.
+func (r *terminalRenderer) fmtFrame(states []*TaskState) []string {
+	if r.l.TaskOutput == OutputTail {
+		states = withTails(states, r.l.TailLines)
+	}
+	var footer []string
+	if r.controls != nil {
+		states = r.controls.view(states)
+		footer = splitCells(r.controls.footer(), r.width)
+	}
+
+	max := r.maxLines()
+	if max > 0 {
+		max -= len(footer)
+	}
+	height := max
+	for {
+		var rows []string
+		for _, s := range fitHeight(states, height) {
+			for _, line := range r.l.formatLines(s, r.lineWidth()) {
+				for _, row := range splitCells(line, r.width) {
+					if s.selected {
+						row = ansiReverse + row + ansiNoReverse
+					}
+					rows = append(rows, row)
+				}
+			}
+		}
+
+		// If some lines were wrapped, the frame may still be
+		// too tall, so try again with fewer task states
+		if max <= 0 || len(rows) <= max || height <= 2 {
+			return append(rows, footer...)
+		}
+		height -= len(rows) - max
+		if height < 2 {
+			height = 2
+		}
+	}
+}
+
+// println clears the last frame, prints `s` in its
+// place, and then redraws the frame below it.
+func (r *terminalRenderer) println(s string) {
+	out := r.fmtMoveUp(len(r.frame)) + ansiClearDown + s + "\n"
+	for _, line := range r.frame {
+		out += line + "\n"
+	}
+	r.write(ansiHideCursor + out + ansiShowCursor)
+}
+
+// finish draws the final task states, or clears
+// the last frame if `clear` is true. The final
+// frame is drawn without the keyboard controls.
+//
+// The line below the frame is cleared too, in case
+// the terminal echoed something there (like "^C").
+func (r *terminalRenderer) finish(states []*TaskState, clear bool) {
+	r.controls = nil
+	if !clear {
+		r.render(states)
+		r.write("\r" + ansiClearLine)
+		return
+	}
+	r.write(r.fmtMoveUp(len(r.frame)) + ansiClearDown)
+	r.frame = nil
+}
+
+// resize re-reads the terminal's size and clears the last frame
+// so that the next render redraws it from scratch, since the
+// terminal may have re-wrapped its lines.
+func (r *terminalRenderer) resize() {
+	if width, height, ok := termSize(r.w); ok {
+		r.width, r.height = width, height
+	}
+	var rows int
+	for _, line := range r.frame {
+		rows += r.rows(line)
+	}
+	r.write(r.fmtMoveUp(rows) + ansiClearDown)
+	r.frame = nil
+}
+
+// rows returns the number of terminal rows `line` takes up,
+// once it's been wrapped to fit the terminal's width
+func (r *terminalRenderer) rows(line string) int {
+	n := cellWidth(line)
+	if r.width <= 0 || n <= r.width {
+		return 1
+	}
+	return (n + r.width - 1) / r.width
+}
+
+// fmtMoveUp returns the escape characters to move the
+// cursor up `n` lines and back to the start of the line.
+func (r *terminalRenderer) fmtMoveUp(n int) string {
+	if n <= 0 {
+		return "\r"
+	}
+	return fmt.Sprintf("\033[%dA\r", n)
+}
+
+// fmtDiff returns the escape characters and text needed to
+// turn the last frame into `lines`, or an empty string if
+// they're the same.
+//
+// Between frames, the cursor sits at the start of the line
+// just below the last frame. fmtDiff moves the cursor up to
+// each line that changed, rewrites it, and then returns the
+// cursor to just below the new frame.
+func (r *terminalRenderer) fmtDiff(lines []string) string {
+	var b strings.Builder
+	old := r.frame
+	row := len(old) // The cursor's current row, relative to the top of the frame
+
+	moveTo := func(target int) {
+		switch {
+		case target < row:
+			fmt.Fprintf(&b, "\033[%dA", row-target)
+		case target > row:
+			fmt.Fprintf(&b, "\033[%dB", target-row)
+		}
+		b.WriteString("\r")
+		row = target
+	}
+
+	// Rewrite the lines that changed
+	for i := 0; i < len(lines) && i < len(old); i++ {
+		if lines[i] == old[i] {
+			continue
+		}
+		moveTo(i)
+		b.WriteString(lines[i] + ansiClearLine)
+	}
+
+	switch {
+	case len(lines) > len(old):
+		// Write the new lines below the old frame
+		moveTo(len(old))
+		for _, line := range lines[len(old):] {
+			b.WriteString(line + ansiClearLine + "\n")
+		}
+		row = len(lines)
+
+	case len(lines) < len(old):
+		// Clear the lines left over from the old frame
+		moveTo(len(lines))
+		b.WriteString(ansiClearDown)
+
+	case b.Len() > 0:
+		// Return to just below the frame
+		moveTo(len(lines))
+	}
+	return b.String()
+}
+
+// logRenderer is a renderer for writers that aren't terminals,
+// like files, pipes, and CI logs. It only appends to the output:
+// a line is printed each time a task's status changes (e.g.
+// "✓ build (2.3s)") and printed text is written in order between
+// them, without any ANSI escape characters.
+type logRenderer struct {
+	l       *List                      // The list, for formatting task states
+	w       io.Writer                  // Where to print
+	status  map[interface{}]TaskStatus // The last status printed for each task
+	started map[interface{}]time.Time  // When each task was seen starting
+}
+
+// newLogRenderer creates a logRenderer that
+// prints the List `l`'s updates to `w`.
+func newLogRenderer(l *List, w io.Writer) *logRenderer {
+	return &logRenderer{
+		l:       l,
+		w:       w,
+		status:  make(map[interface{}]TaskStatus),
+		started: make(map[interface{}]time.Time),
+	}
+}
+
+// render prints a line for each task whose
+// status has changed since the last render.
+func (r *logRenderer) render(states []*TaskState) {
+	for i, s := range states {
+		// Identify the task by its TaskRunner if
+		// possible, otherwise by its position
+		var key interface{} = i
+		if s.runner != nil {
+			key = s.runner
+		}
+
+		last, seen := r.status[key]
+		if s.Status == TaskNotStarted || (seen && last == s.Status) {
+			continue
+		}
+		r.status[key] = s.Status
+
+		// The duration is added below, rather than
+		// as a timer by formatMessage
+		untimed := *s
+		untimed.StartTime = time.Time{}
+		line := stripANSI(r.l.formatMessage(&untimed))
+
+		// Use the task's own timing if it has any, otherwise
+		// time it from when it was seen starting
+		switch start, ok := r.started[key]; {
+		case s.Status == TaskInProgress:
+			r.started[key] = time.Now()
+		case !s.StartTime.IsZero():
+			line += fmt.Sprintf(" (%s)", fmtDuration(s.Elapsed))
+		case ok:
+			line += fmt.Sprintf(" (%s)", fmtDuration(time.Since(start)))
+		}
+		fmt.Fprintln(r.w, line)
+	}
+}
+
+// println prints any status changes that happened
+// before it was called, followed by `s`.
+func (r *logRenderer) println(s string) {
+	r.render(r.l.getTaskStates())
+	fmt.Fprintln(r.w, s)
+}
+
+// finish prints any remaining status changes. The
+// output is never cleared, since it's append-only.
+func (r *logRenderer) finish(states []*TaskState, clear bool) {
+	r.render(states)
+}
+
+// resize is a no-op for the logRenderer
+func (r *logRenderer) resize() {}
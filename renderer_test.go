@@ -0,0 +1,388 @@
+package golist
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// screen is a tiny terminal emulator for checking what the
+// terminalRenderer's output looks like once it's been drawn.
+// It understands the escape sequences the renderer uses.
+type screen struct {
+	rows     [][]rune
+	row, col int
+	width    int // Wrap text onto the next row after this many columns (0 = never)
+}
+
+var ansiSeq = regexp.MustCompile(`^\033\[(\??)(\d*)([a-zA-Z])`)
+
+func (s *screen) Write(p []byte) (int, error) {
+	str := string(p)
+	for len(str) > 0 {
+		if m := ansiSeq.FindStringSubmatch(str); m != nil {
+			str = str[len(m[0]):]
+			n, _ := strconv.Atoi(m[2])
+			if n == 0 {
+				n = 1
+			}
+			s.grow()
+			switch {
+			case m[1] == "?": // Cursor visibility
+			case m[3] == "A":
+				s.row -= n
+				if s.row < 0 {
+					s.row = 0
+				}
+			case m[3] == "B":
+				s.row += n
+			case m[3] == "K":
+				s.rows[s.row] = s.rows[s.row][:min(s.col, len(s.rows[s.row]))]
+			case m[3] == "J":
+				s.rows[s.row] = s.rows[s.row][:min(s.col, len(s.rows[s.row]))]
+				s.rows = s.rows[:s.row+1]
+			}
+			continue
+		}
+		r := []rune(str)[0]
+		str = str[len(string(r)):]
+		switch r {
+		case '\r':
+			s.col = 0
+		case '\n':
+			s.row++
+			s.col = 0
+		default:
+			if s.width > 0 && s.col >= s.width {
+				s.row++
+				s.col = 0
+			}
+			s.grow()
+			for len(s.rows[s.row]) < s.col {
+				s.rows[s.row] = append(s.rows[s.row], ' ')
+			}
+			if s.col < len(s.rows[s.row]) {
+				s.rows[s.row][s.col] = r
+			} else {
+				s.rows[s.row] = append(s.rows[s.row], r)
+			}
+			s.col++
+		}
+	}
+	return len(p), nil
+}
+
+func (s *screen) grow() {
+	for len(s.rows) <= s.row {
+		s.rows = append(s.rows, nil)
+	}
+}
+
+func (s *screen) String() string {
+	lines := make([]string, len(s.rows))
+	for i, r := range s.rows {
+		lines[i] = string(r)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// newTestRenderer creates a terminalRenderer with plain
+// indicators that draws to a screen.
+func newTestRenderer() (*terminalRenderer, *screen) {
+	l := NewList()
+	l.StatusIndicator = StatusIndicators{}
+	s := &screen{}
+	return newTerminalRenderer(l, s), s
+}
+
+func TestTerminalRenderer_Render(t *testing.T) {
+	r, s := newTestRenderer()
+
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	if e := "– t0\n– t1"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	// Change the last line
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1 done"}})
+	if e := "– t0\n– t1 done"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	// Grow the list
+	r.render([]*TaskState{{Message: "t0 done"}, {Message: "t1 done"}, {Message: "t2"}})
+	if e := "– t0 done\n– t1 done\n– t2"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	// Shrink the list
+	r.render([]*TaskState{{Message: "t0"}})
+	if e := "– t0"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_OnlyChangedLines(t *testing.T) {
+	l := NewList()
+	l.StatusIndicator = StatusIndicators{}
+	w := &bytes.Buffer{}
+	r := newTerminalRenderer(l, w)
+
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}, {Message: "t2"}})
+	w.Reset()
+
+	// Nothing changed, so nothing should be written
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}, {Message: "t2"}})
+	if w.Len() != 0 {
+		t.Errorf("expected nothing to be written for an unchanged frame, got %q", w.String())
+	}
+
+	// Only the middle line should be rewritten
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1 done"}, {Message: "t2"}})
+	out := w.String()
+	if strings.Contains(out, "t0") || strings.Contains(out, "t2") {
+		t.Errorf("expected only the changed line to be written, got %q", out)
+	}
+	if !strings.HasPrefix(out, ansiHideCursor) || !strings.HasSuffix(out, ansiShowCursor) {
+		t.Errorf("expected the cursor to be hidden while drawing, got %q", out)
+	}
+}
+
+func TestTerminalRenderer_Println(t *testing.T) {
+	r, s := newTestRenderer()
+
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	r.println("hello")
+	r.println("world")
+	r.render([]*TaskState{{Message: "t0 done"}, {Message: "t1"}})
+	if e := "hello\nworld\n– t0 done\n– t1"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_Finish(t *testing.T) {
+	r, s := newTestRenderer()
+	r.println("before")
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	r.finish([]*TaskState{{Message: "t0"}, {Message: "t1 done"}}, false)
+	if e := "before\n– t0\n– t1 done"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	r, s = newTestRenderer()
+	r.println("before")
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	r.finish([]*TaskState{{Message: "t0"}, {Message: "t1"}}, true)
+	if e := "before"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestList_newRenderer(t *testing.T) {
+	l := NewListWithWriter(&bytes.Buffer{})
+	if _, ok := l.newRenderer().(*logRenderer); !ok {
+		t.Error("expected a non-terminal writer to use the log renderer")
+	}
+
+	l.Display = DisplayTerminal
+	if _, ok := l.newRenderer().(*terminalRenderer); !ok {
+		t.Error("expected DisplayTerminal to use the terminal renderer")
+	}
+
+	l.Display = DisplayLog
+	if _, ok := l.newRenderer().(*logRenderer); !ok {
+		t.Error("expected DisplayLog to use the log renderer")
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	if s := stripANSI(ToGreen("✓") + " done" + ansiClearLine); s != "✓ done" {
+		t.Errorf("expected %q, got %q", "✓ done", s)
+	}
+}
+
+func TestLogRenderer(t *testing.T) {
+	l := NewList()
+	w := &bytes.Buffer{}
+	r := newLogRenderer(l, w)
+
+	t0 := NewTask("t0", nil)
+	t1 := NewTask("t1", nil)
+	l.AddTask(t0).AddTask(t1)
+	states := l.getTaskStates
+
+	r.render(states())
+	if w.Len() != 0 {
+		t.Errorf("expected nothing to be printed before tasks start, got %q", w.String())
+	}
+
+	t0.SetStatus(TaskInProgress)
+	r.render(states())
+	r.render(states())
+	t0.SetStatus(TaskCompleted)
+	t1.SetStatus(TaskFailed)
+	r.println("hello")
+	r.finish(states(), true)
+
+	lines := strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "t0") && !strings.HasSuffix(lines[0], " t0") {
+		t.Errorf("expected t0 to be logged as started, got %q", lines[0])
+	}
+	if e := "✓ t0 ("; !strings.HasPrefix(lines[1], e) || !strings.HasSuffix(lines[1], "s)") {
+		t.Errorf("expected t0 to be logged as completed with its duration, got %q", lines[1])
+	}
+	if e := "✗ t1"; lines[2] != e {
+		t.Errorf("expected %q, got %q", e, lines[2])
+	}
+	if e := "hello"; lines[3] != e {
+		t.Errorf("expected %q, got %q", e, lines[3])
+	}
+	if strings.Contains(w.String(), "\033") {
+		t.Errorf("expected no ANSI escape characters, got %q", w.String())
+	}
+}
+
+func TestList_LogOutput(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Delay = time.Millisecond
+	l.AddTask(NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error {
+			return c.Println("hello")
+		}),
+	}))
+	if err := l.RunAndWait(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := w.String()
+	if strings.Contains(out, "\033") {
+		t.Errorf("expected no ANSI escape characters, got %q", out)
+	}
+	for _, e := range []string{"hello\n", "  ✓ t0 (", "✓ g0 ("} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected output to contain %q, got %q", e, out)
+		}
+	}
+	if strings.Count(out, "✓ t0") != 1 {
+		t.Errorf("expected t0's completion to be logged once, got %q", out)
+	}
+}
+
+func TestTerminalRenderer_MaxHeight(t *testing.T) {
+	r, s := newTestRenderer()
+	r.l.MaxHeight = 2
+
+	r.render([]*TaskState{
+		{Message: "t0", Status: TaskCompleted},
+		{Message: "t1", Status: TaskCompleted},
+		{Message: "t2", Status: TaskInProgress},
+	})
+	if e := "– … 2 completed\n– t2"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_Resize(t *testing.T) {
+	r, s := newTestRenderer()
+
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	r.resize()
+	if s.String() != "" {
+		t.Errorf("expected the frame to be cleared, got %q", s.String())
+	}
+
+	r.render([]*TaskState{{Message: "t0"}, {Message: "t1"}})
+	if e := "– t0\n– t1"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_AutoLineLength(t *testing.T) {
+	r, s := newTestRenderer()
+	r.l.MaxLineLength = AutoLineLength
+	r.width, s.width = 10, 10
+
+	r.render([]*TaskState{{Message: "a long message"}, {Message: "short"}})
+	if e := "– a long…\n– short"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_Wrap(t *testing.T) {
+	r, s := newTestRenderer()
+	r.l.MaxLineLength = AutoLineLength
+	r.l.Overflow = OverflowWrap
+	r.width, s.width = 10, 10
+
+	r.render([]*TaskState{{Message: "a long message"}, {Message: "t1"}})
+	if e := "– a long\n  message\n– t1"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+
+	// The wrapped lines should be cleared
+	r.render([]*TaskState{{Message: "t0"}})
+	if e := "– t0"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_LongLinesClear(t *testing.T) {
+	// Lines longer than the terminal are wrapped by the
+	// terminal, even when they're not being truncated
+	r, s := newTestRenderer()
+	r.l.MaxLineLength = 0
+	r.width, s.width = 10, 10
+
+	r.render([]*TaskState{{Message: "a message that wraps"}, {Message: "t1"}})
+	r.render([]*TaskState{{Message: "t0"}})
+	if e := "– t0"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
+
+func TestTerminalRenderer_ResizeNarrower(t *testing.T) {
+	r, s := newTestRenderer()
+	r.l.MaxLineLength = 0
+	r.width, s.width = 20, 20
+
+	r.render([]*TaskState{{Message: "a message to wrap"}, {Message: "t1"}})
+
+	// Simulate the terminal re-wrapping the frame
+	s.width = 10
+	s.rows = [][]rune{
+		[]rune("– a messag"), []rune("e to wrap"),
+		[]rune("– t1"),
+	}
+	s.row, s.col = 3, 0
+	r.width = 10
+	r.resize()
+	if s.String() != "" {
+		t.Errorf("expected the frame to be cleared, got %q", s.String())
+	}
+}
+
+func TestTerminalRenderer_FinishClearsEcho(t *testing.T) {
+	r, s := newTestRenderer()
+	r.render([]*TaskState{{Message: "t0"}})
+
+	// The terminal echoes Ctrl-C below the list
+	s.Write([]byte("^C"))
+	r.finish([]*TaskState{{Message: "t0"}}, false)
+	if e := "– t0"; s.String() != e {
+		t.Errorf("expected screen %q, got %q", e, s.String())
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package golist
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize relays SIGWINCH signals, which are sent
+// when the terminal is resized, to the channel `c`.
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package golist
+
+import "os"
+
+// notifyResize is a no-op on Windows, which doesn't
+// send a signal when the terminal is resized.
+func notifyResize(c chan<- os.Signal) {}
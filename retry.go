@@ -0,0 +1,75 @@
+package golist
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff represents how the delay between
+// a task's retry attempts grows
+type Backoff int
+
+const (
+	BackoffConstant    Backoff = iota // BackoffConstant waits the same delay between every attempt
+	BackoffExponential                // BackoffExponential doubles the delay after every attempt
+)
+
+// RetryPolicy describes if and how a Task's Action
+// should be retried after it returns an error.
+//
+// The delay before retry `n` (where the first retry is 1) is
+// `Delay` for BackoffConstant and `Delay * 2^(n-1)` for
+// BackoffExponential, limited to `MaxDelay` (if set) and then
+// randomized by `Jitter`.
+type RetryPolicy struct {
+	MaxAttempts int              // Maximum number of times to run the action, including the first attempt
+	Backoff     Backoff          // How the delay between attempts grows
+	Delay       time.Duration    // Delay before the first retry
+	MaxDelay    time.Duration    // Upper limit for the delay between attempts (0 = no limit)
+	Jitter      float64          // Fraction of the delay to randomly add or remove, from 0 to 1 (e.g. 0.2 = ±20%)
+	Retryable   func(error) bool // Reports whether an error should be retried. If nil, all errors are retried
+}
+
+// attempts returns the maximum number of attempts,
+// which is always at least 1
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether the policy allows retrying
+// after attempt number `attempt` failed with `err`
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || err == nil || attempt >= p.attempts() {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+// delay returns how long to wait before retry number `n`,
+// where the first retry is 1
+func (p *RetryPolicy) delay(n int) time.Duration {
+	d := p.Delay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < n && d < math.MaxInt64/2; i++ {
+			d *= 2
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		j := p.Jitter
+		if j > 1 {
+			j = 1
+		}
+		d += time.Duration(float64(d) * j * (rand.Float64()*2 - 1))
+	}
+	return d
+}
@@ -0,0 +1,88 @@
+package golist
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_attempts(t *testing.T) {
+	var p *RetryPolicy
+	if n := p.attempts(); n != 1 {
+		t.Errorf("expected a nil policy to allow 1 attempt, got %d", n)
+	}
+	p = &RetryPolicy{}
+	if n := p.attempts(); n != 1 {
+		t.Errorf("expected an empty policy to allow 1 attempt, got %d", n)
+	}
+	p = &RetryPolicy{MaxAttempts: 3}
+	if n := p.attempts(); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryPolicy_shouldRetry(t *testing.T) {
+	errRetry := errors.New("try again")
+	errFatal := errors.New("give up")
+	p := &RetryPolicy{
+		MaxAttempts: 3,
+		Retryable: func(err error) bool {
+			return errors.Is(err, errRetry)
+		},
+	}
+	if !p.shouldRetry(1, errRetry) {
+		t.Error("expected a retryable error to be retried")
+	}
+	if p.shouldRetry(1, errFatal) {
+		t.Error("expected a non-retryable error not to be retried")
+	}
+	if p.shouldRetry(3, errRetry) {
+		t.Error("expected no retries after the last attempt")
+	}
+	if p.shouldRetry(1, nil) {
+		t.Error("expected a nil error not to be retried")
+	}
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	p := &RetryPolicy{
+		Backoff: BackoffConstant,
+		Delay:   time.Second,
+	}
+	for n := 1; n < 4; n++ {
+		if d := p.delay(n); d != time.Second {
+			t.Errorf("expected constant delay %s for retry %d, got %s", time.Second, n, d)
+		}
+	}
+
+	p = &RetryPolicy{
+		Backoff:  BackoffExponential,
+		Delay:    time.Second,
+		MaxDelay: time.Second * 5,
+	}
+	expect := []time.Duration{time.Second, time.Second * 2, time.Second * 4, time.Second * 5, time.Second * 5}
+	for i, e := range expect {
+		if d := p.delay(i + 1); d != e {
+			t.Errorf("expected exponential delay %s for retry %d, got %s", e, i+1, d)
+		}
+	}
+
+	p = &RetryPolicy{
+		Backoff: BackoffExponential,
+		Delay:   time.Second,
+	}
+	if d := p.delay(1000); d <= 0 {
+		t.Errorf("expected a large delay not to overflow, got %s", d)
+	}
+
+	p = &RetryPolicy{
+		Backoff: BackoffConstant,
+		Delay:   time.Second,
+		Jitter:  0.5,
+	}
+	for i := 0; i < 100; i++ {
+		if d := p.delay(1); d < time.Second/2 || d > time.Second*3/2 {
+			t.Fatalf("expected jittered delay to be within 50%% of %s, got %s", time.Second, d)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package golist
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exit is called to end the process when a List
+// that handles signals gets a second signal
+var exit = os.Exit
+
+// notifySignals returns a copy of `ctx` that's cancelled when
+// the process gets SIGINT or SIGTERM (see `HandleSignals`), and
+// a function to stop listening for the signals.
+func (l *List) notifySignals(ctx context.Context) (context.Context, func()) {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	ctx, stop := l.watchSignals(ctx, sigs)
+	return ctx, func() {
+		signal.Stop(sigs)
+		stop()
+	}
+}
+
+// watchSignals returns a copy of `ctx` that's cancelled when the
+// first signal is received from `sigs`, so the run can finish
+// gracefully. If a second signal is received before the returned
+// function is called, the list is stopped (drawing a final frame
+// and restoring the terminal) and the process exits.
+func (l *List) watchSignals(ctx context.Context, sigs <-chan os.Signal) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case sig := <-sigs:
+			l.Stop()
+			exit(exitCode(sig))
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		cancel()
+		close(done)
+	}
+}
+
+// exitCode returns the conventional exit code for a
+// process ended by the signal `sig` (128 + its number)
+func exitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,144 @@
+package golist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newSignalList creates a List with a task that
+// waits for `release` or for its context to be done
+func newSignalList(release <-chan struct{}, started chan<- struct{}) *List {
+	l := NewListWithWriter(&bytes.Buffer{})
+	l.Display = DisplayLog
+	l.AddTask(NewTask("wait", func(c TaskContext) error {
+		close(started)
+		select {
+		case <-release:
+			return nil
+		case <-c.Context().Done():
+			return c.Context().Err()
+		}
+	}))
+	return l
+}
+
+func TestList_watchSignals(t *testing.T) {
+	started := make(chan struct{})
+	l := newSignalList(nil, started)
+
+	sigs := make(chan os.Signal, 2)
+	ctx, stop := l.watchSignals(context.Background(), sigs)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		l.Start()
+		done <- l.RunContext(ctx)
+		l.Stop()
+	}()
+	<-started
+	sigs <- os.Interrupt
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the signal to cancel the run")
+	}
+	if s := l.Tasks[0].GetStatus(); s != TaskCancelled {
+		t.Errorf("expected status %s, got %s", TaskCancelled, s)
+	}
+}
+
+func TestList_watchSignals_Second(t *testing.T) {
+	exited := make(chan int, 1)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	// The task ignores its context being cancelled
+	release := make(chan struct{})
+	started := make(chan struct{})
+	l := newSignalList(release, started)
+	l.Tasks[0].(*Task).Action = func(c TaskContext) error {
+		close(started)
+		<-release
+		return nil
+	}
+
+	sigs := make(chan os.Signal, 2)
+	ctx, stop := l.watchSignals(context.Background(), sigs)
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		l.Start()
+		done <- l.RunContext(ctx)
+	}()
+	<-started
+	sigs <- os.Interrupt
+	sigs <- os.Interrupt
+
+	select {
+	case code := <-exited:
+		if code != exitCode(os.Interrupt) {
+			t.Errorf("expected exit code %d, got %d", exitCode(os.Interrupt), code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second signal to exit")
+	}
+	l.mu.Lock()
+	running := l.running
+	l.mu.Unlock()
+	if running {
+		t.Error("expected the list to be stopped before exiting")
+	}
+
+	close(release)
+	<-done
+}
+
+func TestList_watchSignals_Stopped(t *testing.T) {
+	sigs := make(chan os.Signal, 2)
+	l := NewList()
+	ctx, stop := l.watchSignals(context.Background(), sigs)
+	stop()
+	if ctx.Err() == nil {
+		t.Error("expected the context to be cancelled once stopped")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if c := exitCode(syscall.Signal(2)); c != 130 {
+		t.Errorf("expected exit code 130, got %d", c)
+	}
+	if c := exitCode(syscall.Signal(15)); c != 143 {
+		t.Errorf("expected exit code 143, got %d", c)
+	}
+}
+
+func TestList_HandleSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("can't send signals to the process on windows")
+	}
+	started := make(chan struct{})
+	l := newSignalList(nil, started)
+	l.HandleSignals = true
+
+	go func() {
+		<-started
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(syscall.SIGTERM)
+	}()
+	err := l.RunAndWait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -1,5 +1,7 @@
 package golist
 
+import "sync"
+
 // ToBlack wraps a string in escape characters
 // to format it as black text.
 func ToBlack(s string) string {
@@ -59,16 +61,21 @@ func (si *StaticIndicator) Next() {
 // CycleIndicator implements the Indicator interface and
 // cycles through returning (optionally colorized)
 // characters from a slice.
+//
+// CycleIndicator's methods are safe for concurrent use.
 type CycleIndicator struct {
 	Indicators []rune              // Array of indicator characters
 	index      int                 // Current position in the Indicators array
 	Colorizer  func(string) string // Optional function to colorize the indicator
+	mu         sync.Mutex          // Guards index
 }
 
 // Get returns the current status indicator.
 // If Colorizer is set, calls it on the indicator character.
 func (si *CycleIndicator) Get() string {
+	si.mu.Lock()
 	s := string(si.Indicators[si.index])
+	si.mu.Unlock()
 	if si.Colorizer == nil {
 		return s
 	}
@@ -78,6 +85,8 @@ func (si *CycleIndicator) Get() string {
 // Next increments the current index in the Indicators array
 // and wraps around if passed the end of the array.
 func (si *CycleIndicator) Next() {
+	si.mu.Lock()
+	defer si.mu.Unlock()
 	si.index = (si.index + 1) % len(si.Indicators)
 }
 
@@ -114,6 +123,8 @@ func (si *StatusIndicators) Next() {
 //   – TaskCompleted: "✓" (green)
 //   – TaskFailed: "✗" (red)
 //   – TaskSkipped: "↓" (black)
+//   – TaskTimedOut: "⧗" (red)
+//   – TaskCancelled: "⊘" (yellow)
 //
 func CreateDefaultStatusIndicator() StatusIndicators {
 	return StatusIndicators{
@@ -136,5 +147,13 @@ func CreateDefaultStatusIndicator() StatusIndicators {
 			Indicator: '↓',
 			Colorizer: ToBlack,
 		},
+		TaskTimedOut: &StaticIndicator{
+			Indicator: '⧗',
+			Colorizer: ToRed,
+		},
+		TaskCancelled: &StaticIndicator{
+			Indicator: '⊘',
+			Colorizer: ToYellow,
+		},
 	}
 }
@@ -35,3 +35,11 @@ func TestStaticIndicator(t *testing.T) {
 	}
 }
 
+func TestCreateDefaultStatusIndicator(t *testing.T) {
+	si := CreateDefaultStatusIndicator()
+	for _, s := range []TaskStatus{TaskNotStarted, TaskInProgress, TaskCompleted, TaskFailed, TaskSkipped, TaskTimedOut, TaskCancelled} {
+		if _, ok := si[s]; !ok {
+			t.Errorf("expected a default indicator for status %q", s)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package golist
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSummarySlowest is the default number of
+// slowest tasks listed in a List's summary
+const DefaultSummarySlowest = 5
+
+// Summary returns a report of the List's last run, with:
+//
+// - The number of tasks with each status, and the total time taken
+//
+// - The slowest tasks (up to the List's `SummarySlowest`)
+//
+// - The full error of each task that failed, with its path in the
+// tree of tasks (e.g. "deploy › services › api")
+//
+// Task groups aren't counted, but their own errors (e.g. from
+// timing out) are reported.
+func (l *List) Summary() string {
+	nodes := walkTasks(l.Tasks, nil)
+
+	var b strings.Builder
+	b.WriteString(l.fmtSummaryCounts(nodes))
+	if slowest := l.fmtSummarySlowest(nodes); slowest != "" {
+		b.WriteString("\n\nSlowest tasks:\n" + slowest)
+	}
+	if failures := fmtSummaryFailures(nodes); failures != "" {
+		b.WriteString("\n\nFailures:\n" + failures)
+	}
+	return b.String()
+}
+
+// fmtSummaryCounts formats the number of tasks with each
+// status and the total time taken by the run (e.g. "Ran 12
+// tasks in 1m23s: 9 completed, 1 failed, 2 skipped")
+func (l *List) fmtSummaryCounts(nodes []taskNode) string {
+	var total int
+	counts := make(map[TaskStatus]int)
+	for _, n := range nodes {
+		if !n.group {
+			counts[n.state.Status]++
+			total++
+		}
+	}
+
+	var parts []string
+	for s := TaskNotStarted; s <= TaskCancelled; s++ {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], strings.ToLower(s.String())))
+		}
+	}
+
+	noun := "tasks"
+	if total == 1 {
+		noun = "task"
+	}
+	return fmt.Sprintf("Ran %d %s in %s: %s", total, noun, fmtDuration(wallTime(nodes)), strings.Join(parts, ", "))
+}
+
+// wallTime returns the time from when the first
+// task started to when the last one finished
+func wallTime(nodes []taskNode) time.Duration {
+	var start, end time.Time
+	for _, n := range nodes {
+		s := n.state
+		if s.StartTime.IsZero() {
+			continue
+		}
+		if start.IsZero() || s.StartTime.Before(start) {
+			start = s.StartTime
+		}
+		if e := s.StartTime.Add(s.Elapsed); e.After(end) {
+			end = e
+		}
+	}
+	return end.Sub(start)
+}
+
+// fmtSummarySlowest formats the List's slowest tasks, one per
+// line, or returns an empty string if none ran (or the List's
+// `SummarySlowest` is 0 or negative)
+func (l *List) fmtSummarySlowest(nodes []taskNode) string {
+	if l.SummarySlowest <= 0 {
+		return ""
+	}
+	var ran []taskNode
+	for _, n := range nodes {
+		if !n.group && !n.state.StartTime.IsZero() {
+			ran = append(ran, n)
+		}
+	}
+	sort.SliceStable(ran, func(i, j int) bool {
+		return ran[i].state.Elapsed > ran[j].state.Elapsed
+	})
+	if len(ran) > l.SummarySlowest {
+		ran = ran[:l.SummarySlowest]
+	}
+
+	lines := make([]string, len(ran))
+	for i, n := range ran {
+		lines[i] = fmt.Sprintf("  %8s  %s", fmtDuration(n.state.Elapsed), fmtPath(n.path))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fmtSummaryFailures formats the path, status and full error
+// of each task that has an error, or returns an empty string
+// if there aren't any
+func fmtSummaryFailures(nodes []taskNode) string {
+	var b strings.Builder
+	for i, e := range failures(nodes) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		status := strings.ToLower(e.Status.String())
+		fmt.Fprintf(&b, "  %s (%s):\n", fmtPath(e.Path), status)
+		for _, line := range strings.Split(strings.TrimRight(e.Err.Error(), "\n"), "\n") {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,133 @@
+package golist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestList_Summary(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	l.SummarySlowest = 2
+	l.AddTask(NewTask("fast", func(c TaskContext) error { return nil }))
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		NewTaskGroup("services", []TaskRunner{
+			NewTask("api", func(c TaskContext) error {
+				time.Sleep(20 * time.Millisecond)
+				return errors.New("exit status 1\nconnection refused")
+			}),
+		}),
+		NewTask("slow", func(c TaskContext) error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		}),
+	}))
+	l.AddTask(&Task{Message: "skipped", Skip: func(c TaskContext) bool { return true }})
+	l.RunAndWait()
+
+	s := l.Summary()
+	if !strings.HasPrefix(s, "Ran 4 tasks in ") {
+		t.Errorf("expected the summary to count 4 tasks, got %q", s)
+	}
+	if !strings.Contains(s, ": 2 completed, 1 failed, 1 skipped\n") {
+		t.Errorf("expected the summary to count each status, got %q", s)
+	}
+
+	slowest := s[strings.Index(s, "Slowest tasks:"):strings.Index(s, "Failures:")]
+	if lines := strings.Split(strings.TrimSpace(slowest), "\n"); len(lines) != 3 {
+		t.Errorf("expected 2 slowest tasks, got %q", slowest)
+	} else if !strings.HasSuffix(lines[1], "deploy › slow") || !strings.HasSuffix(lines[2], "deploy › services › api") {
+		t.Errorf("expected the slowest tasks in order, got %q", lines[1:])
+	}
+
+	e := "Failures:\n  deploy › services › api (failed):\n    exit status 1\n    connection refused"
+	if !strings.HasSuffix(s, e) {
+		t.Errorf("expected the summary to end with %q, got %q", e, s)
+	}
+}
+
+func TestList_SummaryNoFailures(t *testing.T) {
+	l := NewList()
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return nil }))
+	l.Writer = &bytes.Buffer{}
+	l.RunAndWait()
+
+	for _, n := range []int{0, -1} {
+		l.SummarySlowest = n
+		s := l.Summary()
+		if strings.Contains(s, "Slowest") || strings.Contains(s, "Failures") {
+			t.Errorf("expected only the counts with SummarySlowest %d, got %q", n, s)
+		}
+		if !strings.HasPrefix(s, "Ran 1 task in ") || !strings.HasSuffix(s, ": 1 completed") {
+			t.Errorf("unexpected summary %q", s)
+		}
+	}
+}
+
+func TestList_ShowSummary(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Display = DisplayLog
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return nil }))
+
+	l.RunAndWait()
+	if strings.Contains(w.String(), "Ran 1 task") {
+		t.Error("expected no summary by default")
+	}
+
+	w.Reset()
+	l.ShowSummary = true
+	l.RunAndWait()
+	if !strings.HasSuffix(w.String(), "\n"+l.Summary()+"\n") {
+		t.Errorf("expected the summary after the list, got %q", w.String())
+	}
+}
+
+func TestList_ShowSummaryJSON(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Display = DisplayJSON
+	l.ShowSummary = true
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return errors.New("oops") }))
+	l.RunAndWait()
+
+	// Every line is still JSON, with the summary last
+	var e jsonEvent
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		e = jsonEvent{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %s", line, err)
+		}
+	}
+	if e.Type != "Summary" || e.Message != l.Summary() {
+		t.Errorf("expected the summary as the last line, got %+v", e)
+	}
+}
+
+func TestList_ShowSummaryTAP(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Display = DisplayTAP
+	l.ShowSummary = true
+	l.AddTask(NewTask("t0", func(c TaskContext) error { return errors.New("oops") }))
+	l.RunAndWait()
+
+	// The summary is written as comments after the plan
+	out := w.String()
+	i := strings.Index(out, "1..1\n")
+	if i < 0 {
+		t.Fatalf("expected a plan, got %q", out)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out[i+len("1..1\n"):]), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			t.Errorf("expected only comments after the plan, got %q", line)
+		}
+	}
+	if !strings.Contains(out, "# Ran 1 task in ") || !strings.Contains(out, "#   t0 (failed):") {
+		t.Errorf("expected the summary as comments, got %q", out)
+	}
+}
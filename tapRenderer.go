@@ -0,0 +1,170 @@
+package golist
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tapRenderer is a renderer that writes the List's results as
+// TAP version 14 (see `DisplayTAP`) while it runs. It's sent the
+// events as an Observer, so its renderer methods do nothing.
+//
+// Each task is written as a test point when it finishes. A task
+// group's test points are written as a subtest, once the whole
+// group has finished, so that concurrent groups don't interleave.
+type tapRenderer struct {
+	w      io.Writer
+	blocks map[TaskRunner]*tapBlock // The output of each running group (nil for the list)
+}
+
+// tapBlock is the output of a list or task
+// group, as it builds up while it runs
+type tapBlock struct {
+	lines []string // The lines written so far, without the group's indentation
+	n     int      // The number of test points so far
+}
+
+// newTAPRenderer creates a tapRenderer that writes to `w`
+func newTAPRenderer(w io.Writer) *tapRenderer {
+	return &tapRenderer{
+		w:      w,
+		blocks: map[TaskRunner]*tapBlock{nil: {}},
+	}
+}
+
+// OnEvent writes the TAP output for the event `e`
+func (r *tapRenderer) OnEvent(e Event) {
+	switch e.Type {
+	case EventListStarted:
+		r.blocks = map[TaskRunner]*tapBlock{nil: {}}
+		r.write(nil, "TAP version 14")
+
+	case EventListStopped:
+		r.write(nil, fmt.Sprintf("1..%d", r.blocks[nil].n))
+
+	case EventTaskStarted:
+		if _, isGroup := e.Task.(*TaskGroup); isGroup {
+			r.blocks[e.Task] = &tapBlock{}
+		}
+
+	case EventPrintln:
+		// Printed lines are written as comments
+		// in the block of the task's group
+		for _, line := range strings.Split(e.Line, "\n") {
+			r.write(e.parent, "# "+line)
+		}
+
+	case EventTaskCompleted, EventTaskFailed, EventTaskSkipped:
+		r.testPoint(e)
+	}
+}
+
+// testPoint writes the test point for a finished task, preceded
+// by its subtest if it's a task group
+func (r *tapRenderer) testPoint(e Event) {
+	if len(e.Path) == 0 || e.Task == nil {
+		return
+	}
+	name := e.Path[len(e.Path)-1]
+
+	var lines []string
+	if sub, ok := r.blocks[e.Task]; ok {
+		delete(r.blocks, e.Task)
+		lines = append(lines, "# Subtest: "+name)
+		for _, line := range sub.lines {
+			lines = append(lines, "    "+line)
+		}
+		lines = append(lines, fmt.Sprintf("    1..%d", sub.n))
+	}
+
+	b := r.block(e.parent)
+	b.n++
+	result := "ok"
+	if e.Type == EventTaskFailed {
+		result = "not ok"
+	}
+	line := fmt.Sprintf("%s %d - %s", result, b.n, tapEscape(name))
+	if e.Type == EventTaskSkipped {
+		line += " # SKIP"
+	}
+	lines = append(lines, line)
+	if e.Type == EventTaskFailed {
+		lines = append(lines, fmtTAPDiagnostics(e)...)
+	}
+	r.write(e.parent, lines...)
+}
+
+// block returns the block for the group `g` (nil for the list)
+func (r *tapRenderer) block(g TaskRunner) *tapBlock {
+	b, ok := r.blocks[g]
+	if !ok {
+		b = &tapBlock{}
+		r.blocks[g] = b
+	}
+	return b
+}
+
+// write adds lines to the block for the group `g`. The
+// list's own lines (with `g` nil) are written straight away.
+func (r *tapRenderer) write(g TaskRunner, lines ...string) {
+	if g != nil {
+		b := r.block(g)
+		b.lines = append(b.lines, lines...)
+		return
+	}
+	for _, line := range lines {
+		fmt.Fprintln(r.w, line)
+	}
+}
+
+// fmtTAPDiagnostics formats the YAML diagnostics
+// block for a task that failed
+func fmtTAPDiagnostics(e Event) []string {
+	// A group's subtest already shows its tasks'
+	// errors, so only its own error is included
+	err := e.Err
+	if tg, ok := e.Task.(*TaskGroup); ok {
+		err = tg.getOwnError()
+	}
+
+	lines := []string{"  ---"}
+	if err != nil {
+		lines = append(lines, "  message: |-")
+		for _, line := range strings.Split(strings.TrimRight(err.Error(), "\n"), "\n") {
+			lines = append(lines, "    "+line)
+		}
+	}
+	lines = append(lines,
+		fmt.Sprintf("  status: %s", e.Status),
+		fmt.Sprintf("  duration_ms: %d", e.Elapsed.Milliseconds()),
+		"  ...",
+	)
+	return lines
+}
+
+// writeTAPComments writes each line of `s` to `w` as a TAP comment
+func writeTAPComments(w io.Writer, s string) {
+	for _, line := range strings.Split(s, "\n") {
+		fmt.Fprintln(w, strings.TrimRight("# "+line, " "))
+	}
+}
+
+// tapEscape escapes the characters in a test
+// point's description that have special meanings
+func tapEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return strings.ReplaceAll(s, "#", "\\#")
+}
+
+// render does nothing, since the results are written as they happen
+func (r *tapRenderer) render(states []*TaskState) {}
+
+// println does nothing, since printed lines are written as events
+func (r *tapRenderer) println(s string) {}
+
+// finish does nothing, since the results are written as they happen
+func (r *tapRenderer) finish(states []*TaskState, clear bool) {}
+
+// resize does nothing, since the output isn't drawn in place
+func (r *tapRenderer) resize() {}
@@ -0,0 +1,109 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestList_TAPOutput(t *testing.T) {
+	w := &bytes.Buffer{}
+	l := NewListWithWriter(w)
+	l.Display = DisplayTAP
+	l.AddTask(NewTask("build #1", func(c TaskContext) error {
+		c.Println("compiling")
+		return nil
+	}))
+	l.AddTask(NewTaskGroup("deploy", []TaskRunner{
+		NewTask("api", func(c TaskContext) error {
+			return errors.New("exit status 1\nconnection refused")
+		}),
+		&Task{Message: "web", Skip: func(c TaskContext) bool { return true }},
+		NewTaskGroup("checks", []TaskRunner{
+			NewTask("health", func(c TaskContext) error {
+				c.Println("healthy")
+				return nil
+			}),
+		}),
+	}))
+	l.RunAndWait()
+
+	e := `TAP version 14
+# compiling
+ok 1 - build \#1
+# Subtest: deploy
+    not ok 1 - api
+      ---
+      message: |-
+        exit status 1
+        connection refused
+      status: Failed
+      duration_ms: 0
+      ...
+    ok 2 - web # SKIP
+    # Subtest: checks
+        # healthy
+        ok 1 - health
+        1..1
+    ok 3 - checks
+    1..3
+not ok 2 - deploy
+  ---
+  status: Failed
+  duration_ms: 0
+  ...
+1..2
+`
+	out := regexp.MustCompile(`duration_ms: \d+`).ReplaceAllString(w.String(), "duration_ms: 0")
+	if out != e {
+		t.Errorf("expected output:\n%s\ngot:\n%s", e, out)
+	}
+}
+
+func TestList_TAPOutputConcurrent(t *testing.T) {
+	// Sibling groups can have the same name
+	for _, names := range [][]string{{"g0", "g1", "g2"}, {"group", "group", "group"}} {
+		w := &bytes.Buffer{}
+		l := NewListWithWriter(w)
+		l.Display = DisplayTAP
+		l.Concurrent = true
+
+		// The groups' tasks wait for each other, so that the
+		// groups are all running at once and their test points
+		// are interleaved
+		var first, second sync.WaitGroup
+		first.Add(len(names))
+		second.Add(len(names))
+		wait := func(wg *sync.WaitGroup) func(TaskContext) error {
+			return func(c TaskContext) error {
+				wg.Done()
+				wg.Wait()
+				return nil
+			}
+		}
+		for _, name := range names {
+			l.AddTask(NewTaskGroup(name, []TaskRunner{
+				NewTask("t0", wait(&first)),
+				NewTask("t1", wait(&second)),
+			}))
+		}
+		l.RunAndWait()
+
+		// Each group's subtest should be written in one piece
+		subtest := regexp.MustCompile(`# Subtest: (\w+)\n    ok 1 - t\d\n    ok 2 - t\d\n    1..2\nok \d - (\w+)\n`)
+		matches := subtest.FindAllStringSubmatch(w.String(), -1)
+		if len(matches) != 3 {
+			t.Fatalf("expected 3 whole subtests, got:\n%s", w.String())
+		}
+		for _, m := range matches {
+			if m[1] != m[2] {
+				t.Errorf("expected subtest %s to be followed by its test point, got %s", m[1], m[2])
+			}
+		}
+		if !bytes.HasSuffix(w.Bytes(), []byte("\n1..3\n")) {
+			t.Errorf("expected a plan for 3 test points, got:\n%s", w.String())
+		}
+	}
+}
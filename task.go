@@ -1,20 +1,89 @@
 package golist
 
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
 type TaskState struct {
-	Message string
-	Status  TaskStatus
-	Depth   int
+	Message     string
+	Status      TaskStatus
+	Depth       int
+	Queued      int           // The number of sub-tasks waiting for a free slot to run, for task groups
+	Attempt     int           // The task's current attempt number, if it has a RetryPolicy
+	MaxAttempts int           // The maximum number of attempts, if the task has a RetryPolicy
+	RetryAt     time.Time     // When the next attempt will start, if the task is waiting to retry
+	StartTime   time.Time     // When the task started running (zero if it hasn't)
+	EndTime     time.Time     // When the task finished running (zero if it hasn't)
+	Elapsed     time.Duration // How long the task has been running, or how long it ran for
+	Progress    *Progress     // How far through its work the task is (nil if it hasn't reported any progress)
+
+	runner    TaskRunner // The TaskRunner the state describes, for telling tasks apart
+	collapsed int        // For summary rows, the number of rows collapsed into this one
+	selected  bool       // Is the task selected, in an interactive List?
+	logLine   bool       // For rows showing a line printed by the task above, in an interactive List
+}
+
+// timing records when a task started and finished running
+type timing struct {
+	start time.Time
+	end   time.Time
+}
+
+// update records the time of a change to the status `s`
+func (tm *timing) update(s TaskStatus) {
+	switch s {
+	case TaskNotStarted:
+		*tm = timing{}
+	case TaskInProgress:
+		tm.start, tm.end = time.Now(), time.Time{}
+	case TaskSkipped:
+	default:
+		if !tm.start.IsZero() && tm.end.IsZero() {
+			tm.end = time.Now()
+		}
+	}
+}
+
+// fill sets the timing fields of the TaskState `s`
+func (tm timing) fill(s *TaskState) {
+	s.StartTime, s.EndTime = tm.start, tm.end
+	switch {
+	case tm.start.IsZero():
+	case tm.end.IsZero():
+		s.Elapsed = time.Since(tm.start)
+	default:
+		s.Elapsed = tm.end.Sub(tm.start)
+	}
 }
 
 // Task represents a task to be run as part
 // of a List or TaskGroup
+//
+// A Task's getters and setters are safe for concurrent use,
+// so its state can be displayed while it's running. Once the
+// Task has started, its `Message` should only be changed
+// through `SetMessage`.
 type Task struct {
-	Message string                  // Message to display to user
-	Action  func(TaskContext) error // The task function to be run
-	Skip    func(TaskContext) bool  // Is run before the task starts. If returns true, the task isn't run
+	ID        string                  // Optional ID for sibling tasks to reference in DependsOn
+	DependsOn []string                // IDs of sibling tasks that must finish successfully before this one runs
+	Message   string                  // Message to display to user
+	Action    func(TaskContext) error // The task function to be run. It should return when the TaskContext's Context is done
+	Skip      func(TaskContext) bool  // Is run before the task starts. If returns true, the task isn't run
+	Timeout   time.Duration           // If set, each attempt's context is cancelled after this long and the task is marked as timed out
+	Retry     *RetryPolicy            // If set, the action is retried according to the policy when it returns an error
 
-	status TaskStatus // The status of the task
-	err    error      // The error returned by the task function
+	mu       sync.RWMutex    // Guards the task's message and state
+	status   TaskStatus      // The status of the task
+	err      error           // The error returned by the task function
+	attempt  int             // The current attempt number
+	retryAt  time.Time       // When the next attempt will start (zero if not waiting)
+	timing   timing          // When the task started and finished
+	progress progressTracker // The task's progress reports
+	output   ringBuffer      // The last lines printed through the task's TaskContext during its last run
+	owner    TaskRunner      // The TaskRunner that embeds the task (e.g. a CommandTask), if any
+	name     string          // The task's name in task paths, from when it last started
 }
 
 // NewTask creates a new Task with the message `m`
@@ -26,85 +95,305 @@ func NewTask(m string, a func(TaskContext) error) *Task {
 	}
 }
 
+// runner returns the TaskRunner that the task's events and
+// states describe: the one that embeds it, if any (so that a
+// CommandTask is reported as itself), otherwise the task
+func (t *Task) runner() TaskRunner {
+	if t.owner != nil {
+		return t.owner
+	}
+	return t
+}
+
 // Run runs the task's action function
+//
+// If the action panics, the task is marked as failed
+// and its error is set to a *PanicError.
 func (t *Task) Run(parentContext TaskContext) error {
 	// Create a TaskContext to pass to `Skip` and `Action`
 	c := t.createContext(parentContext)
 
-	// Check if the task should be skipped
-	if t.Skip != nil && t.Skip(c) {
-		t.status = TaskSkipped
+	// Wait while the List is paused
+	c.gate.wait(c.Context())
+
+	// Check if the task should be skipped, either because
+	// the run was cancelled or because `Skip` says so
+	if c.Context().Err() != nil || (t.Skip != nil && t.Skip(c)) {
+		t.SetStatus(TaskSkipped)
+		c.notify(EventTaskSkipped, t.runner(), nil)
 		return nil
 	}
 
 	// Check that an action function exists
 	if t.Action == nil {
-		t.status = TaskFailed
-		t.err = ErrNilAction
-		return t.err
+		t.SetError(ErrNilAction)
+		t.SetStatus(TaskFailed)
+		c.notify(EventTaskFailed, t.runner(), func(e *Event) { e.Err = ErrNilAction })
+		return ErrNilAction
 	}
 
 	// Set the status to in-progress and run
+	t.resetOutput(c.outputLines)
 	t.SetStatus(TaskInProgress)
-	err := t.Action(c)
+	c.notify(EventTaskStarted, t.runner(), nil)
+	err, timedOut := t.runAttempts(c)
 
 	// Evaluate the error and update the task status
-	if err != nil {
+	switch {
+	case timedOut:
+		t.SetStatus(TaskTimedOut)
+	case err != nil && c.Context().Err() != nil:
+		t.SetStatus(TaskCancelled)
+	case err != nil:
 		t.SetStatus(TaskFailed)
-	} else {
+	default:
 		t.SetStatus(TaskCompleted)
 	}
 
-	// Store the error and return it
+	// Store the error, print the task's lines if they
+	// were held back and it failed, and return the error
 	t.SetError(err)
+	if s := t.GetStatus(); c.outputMode != OutputPassthrough && (s == TaskFailed || s == TaskTimedOut) {
+		t.printOutput(parentContext, c.path)
+	}
+	c.notify(finishedEvent(t.GetStatus()), t.runner(), func(e *Event) { e.Err = err })
 	return err
 }
 
+// runAttempts runs the task's action and, if the task has a
+// RetryPolicy, keeps retrying it while the policy allows.
+// It returns the error from the last attempt.
+//
+// If an attempt times out but its action doesn't return, the next
+// attempt waits for it to return (or for the run to be cancelled),
+// so that attempts never overlap.
+//
+// If the action panics, the panic is recovered and
+// returned as a *PanicError.
+func (t *Task) runAttempts(c *taskContext) (err error, timedOut bool) {
+	action := recoverPanic(t.Action)
+	for attempt := 1; ; attempt++ {
+		t.setAttempt(attempt, time.Time{})
+		var running <-chan error
+		err, timedOut, running = runWithTimeout(c, t.Timeout, action)
+		if c.Context().Err() != nil || !t.Retry.shouldRetry(attempt, err) {
+			return err, timedOut
+		}
+
+		// Wait for a timed out attempt to return
+		if running != nil {
+			select {
+			case <-running:
+			case <-c.Context().Done():
+				return err, timedOut
+			}
+		}
+
+		// Wait before the next attempt
+		d := t.Retry.delay(attempt)
+		t.setAttempt(attempt+1, time.Now().Add(d))
+		c.notify(EventTaskRetried, t.runner(), func(e *Event) {
+			e.Attempt = attempt + 1
+			e.Err = err
+		})
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-c.Context().Done():
+			timer.Stop()
+			t.setAttempt(attempt, time.Time{})
+			return err, timedOut
+		}
+	}
+}
+
+// setName records the task's name in task paths, as it
+// was when it started (see `walkTasks`)
+func (t *Task) setName(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.name = name
+}
+
+// getName returns the task's name in task paths from when
+// it last started, or an empty string if it hasn't run
+func (t *Task) getName() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.name
+}
+
+// setAttempt sets the task's current attempt number and when
+// it will start (or the zero time if it starts now)
+func (t *Task) setAttempt(n int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempt = n
+	t.retryAt = at
+}
+
 // createContext creates a TaskContext for the task
-func (t *Task) createContext(parentContext TaskContext) TaskContext {
-	return &taskContext{
-		setMessage: func(msg string) {
-			t.SetMessage(msg)
-		},
-		println: func(a ...interface{}) error {
-			return parentContext.Println(a...)
-		},
-		printfln: func(f string, a ...interface{}) error {
-			return parentContext.Printfln(f, a...)
-		},
+func (t *Task) createContext(parentContext TaskContext) *taskContext {
+	c := newChildContext(parentContext, t.runner())
+	t.setName(c.path[len(c.path)-1])
+	c.setMessage = func(msg string) {
+		t.SetMessage(msg)
+		c.notify(EventMessageChanged, t.runner(), nil)
+	}
+	c.setProgress = func(current, total int64) {
+		t.SetProgress(current, total)
+		c.notify(EventProgress, t.runner(), nil)
+	}
+	c.println = func(a ...interface{}) error {
+		line := fmt.Sprint(a...)
+		t.addOutput(line)
+		c.notify(EventPrintln, t.runner(), func(e *Event) {
+			e.Line = line
+		})
+		if c.outputMode != OutputPassthrough {
+			return nil
+		}
+		return parentContext.Println(a...)
+	}
+	c.printfln = func(f string, a ...interface{}) error {
+		line := fmt.Sprintf(f, a...)
+		t.addOutput(line)
+		c.notify(EventPrintln, t.runner(), func(e *Event) {
+			e.Line = line
+		})
+		if c.outputMode != OutputPassthrough {
+			return nil
+		}
+		return parentContext.Printfln(f, a...)
 	}
+	return c
+}
+
+// GetID returns the Task's ID
+func (t *Task) GetID() string {
+	return t.ID
+}
+
+// GetDependencies returns the IDs of the
+// tasks this Task depends on
+func (t *Task) GetDependencies() []string {
+	return t.DependsOn
+}
+
+// GetMessage returns the Task's message text
+func (t *Task) GetMessage() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.Message
 }
 
 // SetMessage sets the Task's message text
 func (t *Task) SetMessage(m string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.Message = m
 }
 
 // SetError sets the Task's error value
 func (t *Task) SetError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.err = err
 }
 
 // GetError returns Task's error value, if there is one
 func (t *Task) GetError() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.err
 }
 
 // GetStatus returns the Task's status
 func (t *Task) GetStatus() TaskStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
 	return t.status
 }
 
 // SetStatus sets the Task's status
 func (t *Task) SetStatus(s TaskStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.status = s
+	t.timing.update(s)
+	if s == TaskInProgress {
+		t.progress = progressTracker{}
+	}
+}
+
+// resetOutput clears the lines printed through the task's
+// TaskContext, keeping up to `size` lines from now on (or
+// DefaultOutputBufferLines if it's 0, or all of them if
+// it's negative, like `List.OutputBuffer`)
+func (t *Task) resetOutput(size int) {
+	switch {
+	case size == 0:
+		size = DefaultOutputBufferLines
+	case size < 0:
+		size = 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.output = newRingBuffer(size)
+}
+
+// addOutput records a line printed through the task's TaskContext
+func (t *Task) addOutput(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.output.add(line)
+}
+
+// getOutput returns the last lines printed through
+// the task's TaskContext during its last run
+func (t *Task) getOutput() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.output.get()
+}
+
+// printOutput prints the lines printed through the task's
+// TaskContext during its run, all together, through the
+// TaskContext `c` (if there are any)
+func (t *Task) printOutput(c TaskContext, path []string) {
+	t.mu.RLock()
+	lines, dropped := t.output.get(), t.output.dropped
+	t.mu.RUnlock()
+	if len(lines) > 0 {
+		c.Println(fmtOutput(path, lines, dropped))
+	}
+}
+
+// SetProgress sets how much of the task's work is done, out
+// of the total. A total of 0 means the total isn't known.
+func (t *Task) SetProgress(current, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.update(current, total)
 }
 
 // GetTaskTates returns the TaskState description
 // of the current task
 func (t *Task) GetTaskStates() []*TaskState {
-	return []*TaskState{{
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s := &TaskState{
 		Message: t.Message,
 		Status:  t.status,
-	}}
+		runner:  t.runner(),
+	}
+	t.timing.fill(s)
+	s.Progress = t.progress.get()
+	if t.Retry != nil {
+		s.Attempt = t.attempt
+		s.MaxAttempts = t.Retry.attempts()
+		s.RetryAt = t.retryAt
+	}
+	return []*TaskState{s}
 }
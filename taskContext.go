@@ -1,19 +1,82 @@
 package golist
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
 // TaskContext is the context passed to the Tasks'
 // Action and Skip functions.
 type TaskContext interface {
-	SetMessage(string)                     // Set the task's message
-	Println(...interface{}) error          // Safely print between list updates like `fmt.Println`
-	Printfln(string, ...interface{}) error // Safely print formatted text between list updates like `fmt.Printf` but with a newline character at the end
+	Context() context.Context               // Get the task's context.Context, which is cancelled when the run is cancelled
+	SetMessage(string)                      // Set the task's message
+	SetProgress(current, total int64)       // Report how much of the task's work is done, out of the total (a total of 0 means it's unknown)
+	SetIndeterminateProgress(current int64) // Report how much of the task's work is done, when the total isn't known
+	Println(...interface{}) error           // Safely print between list updates like `fmt.Println`
+	Printfln(string, ...interface{}) error  // Safely print formatted text between list updates like `fmt.Printf` but with a newline character at the end
 }
 
 // taskContext implements the TaskContext interface for
 // being passed to a Task's Action and Skip functions.
 type taskContext struct {
-	setMessage func(string)
-	println    func(...interface{}) error
-	printfln   func(string, ...interface{}) error
+	ctx         context.Context
+	setMessage  func(string)
+	setProgress func(current, total int64)
+	println     func(...interface{}) error
+	printfln    func(string, ...interface{}) error
+	path        []string       // The path of the task the context belongs to
+	runner      TaskRunner     // The task the context belongs to (nil for the List's own context)
+	parent      TaskRunner     // The TaskGroup the task belongs to (nil if it's not in one)
+	observe     func(Event)    // Sends events to the List's observers (nil if there aren't any)
+	gate        *gate          // Holds tasks back from starting while the List is paused (nil if it can't be)
+	outputMode  TaskOutputMode // What to do with the lines printed by tasks
+	outputLines int            // The number of printed lines each task keeps (see `List.OutputBuffer`)
+}
+
+// newChildContext creates a TaskContext for the task `t`
+// to be run with the TaskContext `parent`. The context's
+// functions are left for the caller to set.
+func newChildContext(parent TaskContext, t TaskRunner) *taskContext {
+	c := &taskContext{ctx: parent.Context()}
+	var parentPath []string
+	if p, ok := parent.(*taskContext); ok {
+		parentPath = p.path
+		c.parent = p.runner
+		c.observe = p.observe
+		c.gate = p.gate
+		c.outputMode = p.outputMode
+		c.outputLines = p.outputLines
+	}
+	c.path = append(append([]string{}, parentPath...), taskName(t))
+	c.runner = t
+	return c
+}
+
+// notify sends an event of type `typ` about the task `t` to
+// the List's observers, if it has any. If `update` isn't nil,
+// it's called to fill in the event's other fields.
+func (tc *taskContext) notify(typ EventType, t TaskRunner, update func(*Event)) {
+	if tc.observe == nil {
+		return
+	}
+	e := newTaskEvent(typ, t, tc.path)
+	e.parent = tc.parent
+	if update != nil {
+		update(&e)
+	}
+	tc.observe(e)
+}
+
+// Context returns the context.Context for the task's run.
+//
+// If no context was set, context.Background is returned.
+func (tc *taskContext) Context() context.Context {
+	if tc.ctx == nil {
+		return context.Background()
+	}
+	return tc.ctx
 }
 
 // SetMessage updates the task's status message
@@ -22,6 +85,18 @@ func (tc *taskContext) SetMessage(msg string) {
 	tc.setMessage(msg)
 }
 
+// SetProgress reports how much of the task's
+// work is done, out of the total
+func (tc *taskContext) SetProgress(current, total int64) {
+	tc.setProgress(current, total)
+}
+
+// SetIndeterminateProgress reports how much of the task's
+// work is done, when the total isn't known
+func (tc *taskContext) SetIndeterminateProgress(current int64) {
+	tc.setProgress(current, 0)
+}
+
 // Println prints text safely between list updates
 func (tc *taskContext) Println(a ...interface{}) error {
 	return tc.println(a...)
@@ -31,3 +106,94 @@ func (tc *taskContext) Println(a ...interface{}) error {
 func (tc *taskContext) Printfln(f string, a ...interface{}) error {
 	return tc.printfln(f, a...)
 }
+
+// withCancel returns a copy of the TaskContext `c` whose context
+// is cancelled when the returned cancel function is called (or
+// when the parent context is cancelled).
+func withCancel(c TaskContext) (TaskContext, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(c.Context())
+	if tc, ok := c.(*taskContext); ok {
+		cc := *tc
+		cc.ctx = ctx
+		return &cc, cancel
+	}
+	return &taskContext{
+		ctx:         ctx,
+		setMessage:  c.SetMessage,
+		setProgress: c.SetProgress,
+		println:     c.Println,
+		printfln:    c.Printfln,
+	}, cancel
+}
+
+// runWithTimeout calls `f` with a copy of the TaskContext `c` whose
+// context is cancelled once the timeout `d` passes, and waits for `f`
+// to return.
+//
+// If the timeout passes before `f` returns, runWithTimeout stops waiting
+// and returns an error wrapping ErrTimedOut, with `timedOut` set to true.
+// `f` is left to return on its own once it sees that its context is done,
+// and `running` is the channel its error will be sent on when it does.
+// `running` is nil if `f` has already returned.
+//
+// Likewise, if `c`'s context has a deadline (like an enclosing TaskGroup's
+// timeout) that passes before `f` returns, runWithTimeout stops waiting and
+// returns the context's error, so a deadline bounds tasks whose actions
+// ignore their context. If `c`'s context is cancelled any other way, `f`
+// is waited for, so it can finish up.
+//
+// If `d` is 0 (or negative) and `c`'s context has no deadline, `f` is
+// called with `c` and no timeout.
+func runWithTimeout(c *taskContext, d time.Duration, f func(TaskContext) error) (err error, timedOut bool, running <-chan error) {
+	parent := c.Context()
+	if _, ok := parent.Deadline(); d <= 0 && !ok {
+		return f(c), false, nil
+	}
+
+	ctx, cancel := parent, context.CancelFunc(func() {})
+	if d > 0 {
+		ctx, cancel = context.WithTimeout(parent, d)
+	}
+	defer cancel()
+
+	tc := *c
+	tc.ctx = ctx
+
+	// Only report a timeout if it was this deadline that
+	// passed, rather than the parent being cancelled
+	isTimeout := func() bool {
+		return d > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f(&tc)
+	}()
+
+	select {
+	case err = <-done:
+		// If `f` failed because it ran out of time,
+		// report it as a timeout
+		if err == nil || !isTimeout() {
+			return err, false, nil
+		}
+	case <-ctx.Done():
+		switch {
+		case isTimeout():
+			running = done
+		case errors.Is(parent.Err(), context.DeadlineExceeded):
+			// The parent's deadline passed, so stop waiting
+			// for `f`, unless it's already returned
+			select {
+			case err = <-done:
+				return err, false, nil
+			default:
+				return parent.Err(), false, done
+			}
+		default:
+			// The parent was cancelled, so wait for `f` to finish up
+			return <-done, false, nil
+		}
+	}
+	return fmt.Errorf("%w after %s", ErrTimedOut, d), true, running
+}
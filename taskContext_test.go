@@ -1,6 +1,9 @@
 package golist
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestTaskContext(t *testing.T) {
 	m := "my message"
@@ -26,3 +29,17 @@ func TestTaskContext(t *testing.T) {
 		}
 	}(c)
 }
+
+func TestTaskContext_Context(t *testing.T) {
+	c := &taskContext{}
+	if c.Context() == nil {
+		t.Error("expected a non-nil default context")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c = &taskContext{ctx: ctx}
+	if c.Context() != ctx {
+		t.Error("expected the task context's context to be returned")
+	}
+}
@@ -1,8 +1,11 @@
 package golist
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewTask_RunComplete(t *testing.T) {
@@ -154,3 +157,324 @@ func TestTask_createContext(t *testing.T) {
 		t.Error("printfln function never called")
 	}
 }
+
+func TestTask_Output(t *testing.T) {
+	c := &taskContext{
+		println:  func(a ...interface{}) error { return nil },
+		printfln: func(f string, a ...interface{}) error { return nil },
+	}
+	k := NewTask("t", func(c TaskContext) error {
+		c.Println("hello", "world")
+		c.Printfln("%d lines", 2)
+		return nil
+	})
+	k.Run(c)
+
+	expect := []string{"helloworld", "2 lines"}
+	out := k.getOutput()
+	if len(out) != len(expect) || out[0] != expect[0] || out[1] != expect[1] {
+		t.Errorf("expected output %q, got %q", expect, out)
+	}
+
+	// The output is cleared when the task runs again
+	k.Action = func(c TaskContext) error { return nil }
+	k.Run(c)
+	if out := k.getOutput(); len(out) != 0 {
+		t.Errorf("expected the output to be cleared, got %q", out)
+	}
+}
+
+func TestTask_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	k := NewTask("test", func(c TaskContext) error {
+		t.Error("task should have been skipped")
+		return nil
+	})
+	if err := k.Run(&taskContext{ctx: ctx}); err != nil {
+		t.Errorf("expected no error, got %q", err)
+	}
+	if s := k.GetStatus(); s != TaskSkipped {
+		t.Errorf("expected status %q, got %q", TaskSkipped, s)
+	}
+}
+
+func TestTask_ContextInherited(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	k := NewTask("test", func(c TaskContext) error {
+		if v := c.Context().Value(key{}); v != "value" {
+			t.Errorf("expected the parent context to be passed down, got %v", v)
+		}
+		return nil
+	})
+	k.Run(&taskContext{ctx: ctx})
+}
+
+func TestTask_Timeout(t *testing.T) {
+	k := &Task{
+		Message: "test",
+		Timeout: time.Millisecond * 10,
+		Action: func(c TaskContext) error {
+			<-c.Context().Done()
+			return c.Context().Err()
+		},
+	}
+	err := k.Run(&taskContext{})
+	if !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+	if s := k.GetStatus(); s != TaskTimedOut {
+		t.Errorf("expected status %q, got %q", TaskTimedOut, s)
+	}
+}
+
+func TestTask_TimeoutHungAction(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	k := &Task{
+		Message: "test",
+		Timeout: time.Millisecond * 10,
+		Action: func(c TaskContext) error {
+			<-release // Ignores the context
+			return nil
+		},
+	}
+	if err := k.Run(&taskContext{}); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+	if s := k.GetStatus(); s != TaskTimedOut {
+		t.Errorf("expected status %q, got %q", TaskTimedOut, s)
+	}
+}
+
+func TestTask_TimeoutNotReached(t *testing.T) {
+	k := &Task{
+		Message: "test",
+		Timeout: time.Second,
+		Action: func(c TaskContext) error {
+			return nil
+		},
+	}
+	if err := k.Run(&taskContext{}); err != nil {
+		t.Errorf("expected no error, got %q", err)
+	}
+	if s := k.GetStatus(); s != TaskCompleted {
+		t.Errorf("expected status %q, got %q", TaskCompleted, s)
+	}
+}
+
+func TestTask_Retry(t *testing.T) {
+	var n int
+	k := &Task{
+		Message: "test",
+		Retry: &RetryPolicy{
+			MaxAttempts: 3,
+			Delay:       time.Millisecond,
+		},
+		Action: func(c TaskContext) error {
+			n++
+			if n < 3 {
+				return errors.New("flaky")
+			}
+			return nil
+		},
+	}
+	if err := k.Run(&taskContext{}); err != nil {
+		t.Errorf("expected no error after retrying, got %q", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+	if s := k.GetStatus(); s != TaskCompleted {
+		t.Errorf("expected status %q, got %q", TaskCompleted, s)
+	}
+	if s := k.GetTaskStates()[0]; s.Attempt != 3 || s.MaxAttempts != 3 {
+		t.Errorf("expected attempt 3/3, got %d/%d", s.Attempt, s.MaxAttempts)
+	}
+}
+
+func TestTask_RetryNotRetryable(t *testing.T) {
+	var n int
+	expect := errors.New("fatal")
+	k := &Task{
+		Message: "test",
+		Retry: &RetryPolicy{
+			MaxAttempts: 5,
+			Retryable: func(err error) bool {
+				return err != expect
+			},
+		},
+		Action: func(c TaskContext) error {
+			n++
+			return expect
+		},
+	}
+	if err := k.Run(&taskContext{}); err != expect {
+		t.Errorf("expected error %q, got %q", expect, err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+	if s := k.GetStatus(); s != TaskFailed {
+		t.Errorf("expected status %q, got %q", TaskFailed, s)
+	}
+}
+
+func TestTask_RetryCancelledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var n int
+	k := &Task{
+		Message: "test",
+		Retry: &RetryPolicy{
+			MaxAttempts: 5,
+			Delay:       time.Hour,
+		},
+		Action: func(c TaskContext) error {
+			n++
+			go cancel()
+			return errors.New("flaky")
+		},
+	}
+	if err := k.Run(&taskContext{ctx: ctx}); err == nil {
+		t.Error("expected an error")
+	}
+	if n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+}
+
+func TestTask_RetryTimeout(t *testing.T) {
+	var attempts, running, maxRunning int32
+	k := &Task{
+		Message: "test",
+		Timeout: 30 * time.Millisecond,
+		Retry:   &RetryPolicy{MaxAttempts: 3},
+		Action: func(c TaskContext) error {
+			atomic.AddInt32(&attempts, 1)
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(60 * time.Millisecond) // Ignores the context
+			return nil
+		},
+	}
+	if err := k.Run(&taskContext{}); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+	if n := atomic.LoadInt32(&maxRunning); n != 1 {
+		t.Errorf("expected 1 attempt at a time, got %d", n)
+	}
+}
+
+func TestTask_Panic(t *testing.T) {
+	k := NewTask("test", func(c TaskContext) error {
+		var m map[string]int
+		m["oops"]++
+		return nil
+	})
+
+	err := k.Run(&taskContext{})
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a *PanicError, got %T", err)
+	}
+	if s := k.GetStatus(); s != TaskFailed {
+		t.Errorf("expected status %q, got %q", TaskFailed, s)
+	}
+	if k.GetError() != err {
+		t.Errorf("expected the task's error to be the panic error")
+	}
+}
+
+func TestTask_Timing(t *testing.T) {
+	task := NewTask("t0", func(c TaskContext) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	s := task.GetTaskStates()[0]
+	if !s.StartTime.IsZero() || !s.EndTime.IsZero() || s.Elapsed != 0 {
+		t.Errorf("expected no timing before running, got %+v", s)
+	}
+
+	if err := task.Run(&taskContext{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	s = task.GetTaskStates()[0]
+	if s.StartTime.IsZero() || s.EndTime.IsZero() {
+		t.Fatalf("expected start and end times, got %+v", s)
+	}
+	if s.Elapsed != s.EndTime.Sub(s.StartTime) || s.Elapsed < 20*time.Millisecond {
+		t.Errorf("expected elapsed time of at least 20ms, got %s", s.Elapsed)
+	}
+}
+
+func TestTask_TimingRunning(t *testing.T) {
+	started := make(chan bool)
+	done := make(chan bool)
+	task := NewTask("t0", func(c TaskContext) error {
+		close(started)
+		<-done
+		return nil
+	})
+	go task.Run(&taskContext{})
+	<-started
+	defer close(done)
+
+	time.Sleep(10 * time.Millisecond)
+	s := task.GetTaskStates()[0]
+	if s.StartTime.IsZero() || !s.EndTime.IsZero() {
+		t.Fatalf("expected a start time and no end time, got %+v", s)
+	}
+	if s.Elapsed < 10*time.Millisecond {
+		t.Errorf("expected the elapsed time to be live, got %s", s.Elapsed)
+	}
+}
+
+func TestTask_TimingSkipped(t *testing.T) {
+	task := NewTask("t0", func(c TaskContext) error { return nil })
+	task.Skip = func(c TaskContext) bool { return true }
+	task.Run(&taskContext{})
+
+	if s := task.GetTaskStates()[0]; !s.StartTime.IsZero() || s.Elapsed != 0 {
+		t.Errorf("expected no timing for a skipped task, got %+v", s)
+	}
+}
+
+func TestTask_SetProgress(t *testing.T) {
+	task := NewTask("t0", func(c TaskContext) error {
+		c.SetProgress(3, 10)
+		return nil
+	})
+	if task.GetTaskStates()[0].Progress != nil {
+		t.Error("expected no progress before running")
+	}
+	task.Run(&taskContext{})
+
+	p := task.GetTaskStates()[0].Progress
+	if p == nil || p.Current != 3 || p.Total != 10 {
+		t.Errorf("expected progress of 3/10, got %+v", p)
+	}
+
+	// Progress is reset when the task is run again
+	task.Action = func(c TaskContext) error {
+		c.SetIndeterminateProgress(42)
+		return nil
+	}
+	task.Run(&taskContext{})
+	p = task.GetTaskStates()[0].Progress
+	if p == nil || p.Current != 42 || !p.Indeterminate() {
+		t.Errorf("expected indeterminate progress of 42, got %+v", p)
+	}
+}
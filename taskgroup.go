@@ -2,21 +2,38 @@ package golist
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 )
 
 // TaskGroup represents a group of TaskRunners
 // for running nested tasks within a TaskList
+//
+// A TaskGroup's getters and setters are safe for concurrent
+// use, so its state can be displayed while it's running. Once
+// the TaskGroup has started, its `Message` should only be
+// changed through `SetMessage` and its `Tasks` shouldn't change.
 type TaskGroup struct {
+	ID                      string                 // Optional ID for sibling tasks to reference in DependsOn
+	DependsOn               []string               // IDs of sibling tasks that must finish successfully before this group runs
 	Message                 string                 // The message to be displayed
 	Tasks                   []TaskRunner           // A list of tasks to run
 	Skip                    func(TaskContext) bool // Is run before the task starts. If returns true, the task isn't run
-	FailOnError             bool                   // If true, the task group stops on the first error
+	FailOnError             bool                   // If true, the task group stops on the first error (and cancels any running tasks, if concurrent)
 	HideTasksWhenNotRunning bool                   // If true, the task group only show its sub-task-runners when actively running
-	Concurrent              bool                   // Should the tasks be run concurrently?
+	Concurrent              bool                   // Should the tasks be run concurrently? Ignored if tasks have dependencies
+	Timeout                 time.Duration          // If set, the group's context is cancelled after this long and the group is marked as timed out (see `Run`)
+	MaxConcurrency          int                    // Maximum number of tasks to run at once when running concurrently (0 = no limit)
 
-	status TaskStatus // The status of the task
+	mu       sync.RWMutex    // Guards the group's message and state
+	status   TaskStatus      // The status of the task
+	err      error           // The group's own error (e.g. from timing out)
+	queued   int32           // Number of tasks waiting for a free slot to run (accessed atomically)
+	timing   timing          // When the group started and finished
+	progress progressTracker // The group's own progress reports, if any
+	name     string          // The group's name in task paths, from when it last started
 }
 
 // NewTaskGroup creates a new TaskGroup
@@ -39,8 +56,8 @@ func (tg *TaskGroup) AddTask(t TaskRunner) *TaskGroup {
 func (tg *TaskGroup) runSync(c TaskContext) error {
 	var skipRemaining bool
 	for _, t := range tg.Tasks {
-		if skipRemaining {
-			t.SetStatus(TaskSkipped)
+		if skipRemaining || c.Context().Err() != nil {
+			skipTask(c, t)
 			continue
 		}
 		err := t.Run(c)
@@ -52,91 +69,291 @@ func (tg *TaskGroup) runSync(c TaskContext) error {
 }
 
 // runAsync runs the TaskRunners in this TaskGroup concurrently
-// and blocks until they are all done.
+// (at most `MaxConcurrency` at a time) and blocks until they
+// are all done.
+//
+// If `FailOnError` is true, the first error cancels the context
+// shared by the tasks, so tasks that are still running are
+// cancelled and tasks that haven't started are skipped.
 func (tg *TaskGroup) runAsync(c TaskContext) error {
+	c, cancel := withCancel(c)
+	defer cancel()
+
+	p := newWorkerPool(tg.MaxConcurrency, &tg.queued)
+	defer p.close()
+
 	var wg sync.WaitGroup
 	for _, t := range tg.Tasks {
 		wg.Add(1)
-		go func(t TaskRunner) {
+		t := t
+		p.submit(func() {
 			defer wg.Done()
-			t.Run(c)
-		}(t)
+			if err := t.Run(c); err != nil && tg.FailOnError {
+				cancel()
+			}
+		})
 	}
 	wg.Wait()
 	return tg.GetError()
 }
 
+// runGraph runs the TaskRunners in this TaskGroup according
+// to their dependencies and blocks until they are all done.
+func (tg *TaskGroup) runGraph(c TaskContext) error {
+	p := newWorkerPool(tg.MaxConcurrency, &tg.queued)
+	defer p.close()
+	runGraph(c, tg.Tasks, tg.FailOnError, p)
+	return tg.GetError()
+}
+
+// Validate checks that the dependencies between this TaskGroup's
+// tasks (and between the tasks in any nested TaskGroups) are valid.
+func (tg *TaskGroup) Validate() error {
+	return validateGraph(tg.Tasks)
+}
+
 // Run runs the TaskRunners in this TaskGroup
+//
+// If any of the group's tasks declare dependencies, each one is
+// started as soon as its dependencies have finished, regardless
+// of `Concurrent`.
+//
+// If the group has a `Timeout`, its tasks are cancelled once it
+// passes, and the group waits for them to return before it's marked
+// as timed out. A Task whose action ignores its context stops being
+// waited for at the timeout, and is marked as cancelled, though its
+// action is left running in the background. The timeout can't bound
+// other TaskRunners that ignore their context.
 func (tg *TaskGroup) Run(parentContext TaskContext) error {
 	// Create a context
 	c := tg.createContext(parentContext)
 
-	// Check if the task should be skipped
-	if tg.Skip != nil && tg.Skip(c) {
+	// Check if the task should be skipped, either because
+	// the run was cancelled or because `Skip` says so
+	if c.Context().Err() != nil || (tg.Skip != nil && tg.Skip(c)) {
 		tg.SetStatus(TaskSkipped)
+		c.notify(EventTaskSkipped, tg, nil)
 		return nil
 	}
 
+	// Check the task dependencies before running anything
+	if err := tg.Validate(); err != nil {
+		tg.setError(err)
+		tg.SetStatus(TaskFailed)
+		c.notify(EventTaskFailed, tg, func(e *Event) { e.Err = err })
+		return err
+	}
+
 	// Prepare to run
 	tg.SetStatus(TaskInProgress)
+	c.notify(EventTaskStarted, tg, nil)
 
-	var err error
-	if tg.Concurrent {
+	run := tg.runSync
+	if hasDependencies(tg.Tasks) {
+		// Run by dependencies...
+		run = tg.runGraph
+	} else if tg.Concurrent {
 		// Run concurrently...
-		err = tg.runAsync(c)
-	} else {
-		// Otherwise, run synchronously
-		err = tg.runSync(c)
+		run = tg.runAsync
+	}
+	err, timedOut, running := runWithTimeout(c, tg.Timeout, run)
+	if running != nil {
+		// Wait for the group's tasks to return, rather than leaving
+		// them running (and changing status) after the group has
+		// finished. They return promptly, since the timeout is the
+		// deadline of their context too.
+		<-running
 	}
 
-	// Update the TaskGroup's status
-	if err != nil {
+	// Update the TaskGroup's status. If the group was interrupted,
+	// it's only marked as cancelled if none of its tasks failed
+	// on their own.
+	switch {
+	case timedOut:
+		tg.setError(err)
+		tg.SetStatus(TaskTimedOut)
+	case err != nil && c.Context().Err() != nil && !tg.hasFailures():
+		tg.SetStatus(TaskCancelled)
+	case err != nil:
 		tg.SetStatus(TaskFailed)
-	} else {
+	default:
 		tg.SetStatus(TaskCompleted)
 	}
+	c.notify(finishedEvent(tg.GetStatus()), tg, func(e *Event) { e.Err = err })
 
 	// Return the error
 	return err
 }
 
+// hasFailures returns true if any of the group's
+// tasks failed or timed out
+func (tg *TaskGroup) hasFailures() bool {
+	for _, t := range tg.Tasks {
+		switch t.GetStatus() {
+		case TaskFailed, TaskTimedOut:
+			return true
+		}
+	}
+	return false
+}
+
+// setName records the group's name in task paths, as
+// it was when it started (see `walkTasks`)
+func (tg *TaskGroup) setName(name string) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.name = name
+}
+
+// getName returns the group's name in task paths from when
+// it last started, or an empty string if it hasn't run
+func (tg *TaskGroup) getName() string {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+	return tg.name
+}
+
 // createContext creates a TaskContext for the task
-func (t *TaskGroup) createContext(parentContext TaskContext) TaskContext {
-	return &taskContext{
-		setMessage: func(msg string) {
-			t.SetMessage(msg)
-		},
-		println: func(a ...interface{}) error {
-			return parentContext.Println(a...)
-		},
-		printfln: func(f string, a ...interface{}) error {
-			return parentContext.Printfln(f, a...)
-		},
+func (t *TaskGroup) createContext(parentContext TaskContext) *taskContext {
+	c := newChildContext(parentContext, t)
+	t.setName(c.path[len(c.path)-1])
+	c.setMessage = func(msg string) {
+		t.SetMessage(msg)
+		c.notify(EventMessageChanged, t, nil)
+	}
+	c.setProgress = func(current, total int64) {
+		t.SetProgress(current, total)
+		c.notify(EventProgress, t, nil)
 	}
+	c.println = func(a ...interface{}) error {
+		return parentContext.Println(a...)
+	}
+	c.printfln = func(f string, a ...interface{}) error {
+		return parentContext.Printfln(f, a...)
+	}
+	return c
+}
+
+// GetID returns the TaskGroup's ID
+func (tg *TaskGroup) GetID() string {
+	return tg.ID
+}
+
+// GetDependencies returns the IDs of the
+// tasks this TaskGroup depends on
+func (tg *TaskGroup) GetDependencies() []string {
+	return tg.DependsOn
+}
+
+// GetMessage returns the display message for this TaskGroup
+func (tg *TaskGroup) GetMessage() string {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+	return tg.Message
 }
 
 // SetMessage sets the display message for this TaskGroup
 func (tg *TaskGroup) SetMessage(m string) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
 	tg.Message = m
 }
 
-// GetError returns this TaskGroup's errors, if any
+// setError sets this TaskGroup's own error value
+func (tg *TaskGroup) setError(err error) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.err = err
+}
+
+// getOwnError returns the TaskGroup's own error (e.g.
+// from timing out), not including its tasks' errors
+func (tg *TaskGroup) getOwnError() error {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+	return tg.err
+}
+
+// GetError returns this TaskGroup's errors, if any, which
+// includes its own error (e.g. from timing out) followed
+// by the errors from its sub-tasks.
 func (tg *TaskGroup) GetError() error {
+	tg.mu.RLock()
 	var err *multierror.Error
+	err = multierror.Append(err, tg.err)
+	tg.mu.RUnlock()
 	for _, t := range tg.Tasks {
 		err = multierror.Append(err, t.GetError())
 	}
 	return err.ErrorOrNil()
 }
 
+// Failures returns a TaskError for the TaskGroup's own error
+// (if it has one) and for each of its tasks that has an error,
+// in tree order. The paths start with the TaskGroup's name.
+func (tg *TaskGroup) Failures() []*TaskError {
+	return failures(walkTasks([]TaskRunner{tg}, nil))
+}
+
 // GetStatus returns this TaskGroup's TaskStatus
 func (tg *TaskGroup) GetStatus() TaskStatus {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
 	return tg.status
 }
 
 // GetStatus sets this TaskGroup's TaskStatus
 func (tg *TaskGroup) SetStatus(s TaskStatus) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
 	tg.status = s
+	tg.timing.update(s)
+	if s == TaskInProgress {
+		tg.progress = progressTracker{}
+	}
+}
+
+// SetProgress sets how much of the group's work is done, out
+// of the total. A total of 0 means the total isn't known.
+//
+// If it isn't set, the group's progress is the number
+// of its tasks that have finished.
+func (tg *TaskGroup) SetProgress(current, total int64) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.progress.update(current, total)
+}
+
+// getTiming returns when the group started and finished
+func (tg *TaskGroup) getTiming() timing {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+	return tg.timing
+}
+
+// getProgress returns the group's progress, either as set with
+// SetProgress or from the number of its tasks that have finished
+func (tg *TaskGroup) getProgress() *Progress {
+	tg.mu.RLock()
+	p, start := tg.progress, tg.timing.start
+	tg.mu.RUnlock()
+	if p.set || start.IsZero() || len(tg.Tasks) == 0 {
+		return p.get()
+	}
+
+	var done int64
+	for _, t := range tg.Tasks {
+		if s := t.GetStatus(); s != TaskNotStarted && s != TaskInProgress {
+			done++
+		}
+	}
+	p = progressTracker{
+		set:     true,
+		current: done,
+		total:   int64(len(tg.Tasks)),
+		firstAt: start,
+	}
+	return p.get()
 }
 
 // GetTaskStates returns a slice of TaskStates for this TaskGroup
@@ -145,11 +362,17 @@ func (tg *TaskGroup) SetStatus(s TaskStatus) {
 // a TaskRunners message, status, and tree-depth, and are passed up to
 // the parent List for printing.
 func (tg *TaskGroup) GetTaskStates() []*TaskState {
-	messages := []*TaskState{{
-		Status:  tg.GetStatus(),
-		Message: tg.Message,
-	}}
-	if !tg.HideTasksWhenNotRunning || tg.GetStatus() == TaskInProgress {
+	status := tg.GetStatus()
+	header := &TaskState{
+		Status:  status,
+		Message: tg.GetMessage(),
+		Queued:  int(atomic.LoadInt32(&tg.queued)),
+		runner:  tg,
+	}
+	tg.getTiming().fill(header)
+	header.Progress = tg.getProgress()
+	messages := []*TaskState{header}
+	if !tg.HideTasksWhenNotRunning || status == TaskInProgress {
 		for _, t := range tg.Tasks {
 			msgs := t.GetTaskStates()
 			for _, m := range msgs {
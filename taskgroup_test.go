@@ -1,8 +1,12 @@
 package golist
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewTaskGroup(t *testing.T) {
@@ -119,3 +123,271 @@ func TestTaskGroup_createContext(t *testing.T) {
 		t.Error("expected Printfln to be called")
 	}
 }
+
+func TestTaskGroup_Timeout(t *testing.T) {
+	var t1Ran int32
+	g := &TaskGroup{
+		Message: "test",
+		Timeout: time.Millisecond * 10,
+		Tasks: []TaskRunner{
+			NewTask("t0", func(c TaskContext) error {
+				<-c.Context().Done()
+				return c.Context().Err()
+			}),
+			NewTask("t1", func(c TaskContext) error {
+				atomic.StoreInt32(&t1Ran, 1)
+				return nil
+			}),
+		},
+	}
+
+	err := g.Run(&taskContext{})
+	if !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+	if s := g.GetStatus(); s != TaskTimedOut {
+		t.Errorf("expected status %q, got %q", TaskTimedOut, s)
+	}
+	if !errors.Is(g.GetError(), ErrTimedOut) {
+		t.Errorf("expected group error to include %q, got %q", ErrTimedOut, g.GetError())
+	}
+	if atomic.LoadInt32(&t1Ran) == 1 {
+		t.Error("t1 shouldn't have run after the group timed out")
+	}
+}
+
+func TestTaskGroup_TimeoutWaitsForTasks(t *testing.T) {
+	late := errors.New("late")
+	g := NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error {
+			time.Sleep(200 * time.Millisecond) // Ignores the context
+			return late
+		}),
+		NewTask("t1", func(c TaskContext) error {
+			<-c.Context().Done()
+			return c.Context().Err()
+		}),
+	})
+	g.Concurrent = true
+	g.Timeout = 10 * time.Millisecond
+
+	l := NewListWithWriter(&strings.Builder{})
+	l.Display = DisplayLog
+	l.AddTask(g)
+	if err := l.RunAndWait(); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+
+	// The tasks have finished by the time the run returns
+	expect := []TaskStatus{TaskCancelled, TaskCancelled}
+	for i, task := range g.Tasks {
+		if s := task.GetStatus(); s != expect[i] {
+			t.Errorf("expected task %d to be %q, got %q", i, expect[i], s)
+		}
+	}
+	fs := l.Failures()
+	time.Sleep(250 * time.Millisecond)
+	for i, task := range g.Tasks {
+		if s := task.GetStatus(); s != expect[i] {
+			t.Errorf("expected task %d to stay %q, got %q", i, expect[i], s)
+		}
+	}
+	if later := l.Failures(); len(later) != len(fs) {
+		t.Errorf("expected the failures not to change after the run, got %v then %v", fs, later)
+	}
+}
+
+func TestTaskGroup_TimeoutHungTask(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	g := NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error {
+			select { // Ignores the context
+			case <-release:
+			case <-time.After(2 * time.Second):
+			}
+			return nil
+		}),
+	})
+	g.Timeout = 50 * time.Millisecond
+
+	l := NewListWithWriter(&strings.Builder{})
+	l.Display = DisplayLog
+	l.Delay = time.Millisecond
+	l.AddTask(g)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The group's timeout bounds the task, even though
+	// it doesn't return when its context is done
+	start := time.Now()
+	if err := l.RunAndWaitContext(ctx); !errors.Is(err, ErrTimedOut) {
+		t.Errorf("expected error %q, got %q", ErrTimedOut, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected the run to end at the group's timeout, took %s", d)
+	}
+	if s := g.GetStatus(); s != TaskTimedOut {
+		t.Errorf("expected the group to be %q, got %q", TaskTimedOut, s)
+	}
+	if s := g.Tasks[0].GetStatus(); s != TaskCancelled {
+		t.Errorf("expected the task to be %q, got %q", TaskCancelled, s)
+	}
+}
+
+func TestTaskGroup_MaxConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	g := &TaskGroup{
+		Message:        "test",
+		Concurrent:     true,
+		MaxConcurrency: 1,
+	}
+	for i := 0; i < 3; i++ {
+		g.AddTask(NewTask("task", func(c TaskContext) error {
+			<-release
+			return nil
+		}))
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- g.Run(&taskContext{})
+	}()
+	for atomic.LoadInt32(&g.queued) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if q := g.GetTaskStates()[0].Queued; q != 2 {
+		t.Errorf("expected the group to show 2 queued tasks, got %d", q)
+	}
+	l := NewList()
+	if m, e := l.formatMessage(g.GetTaskStates()[0]), "test (2 queued) ["; !strings.Contains(m, e) {
+		t.Errorf("expected the group's message to contain %q, got %q", e, m)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTaskGroup_ConcurrentFailOnError(t *testing.T) {
+	started := make(chan struct{})
+	inner := NewTaskGroup("inner", []TaskRunner{
+		NewTask("t2", func(c TaskContext) error {
+			close(started)
+			<-c.Context().Done()
+			return c.Context().Err()
+		}),
+	})
+	g := &TaskGroup{
+		Message:     "test",
+		Concurrent:  true,
+		FailOnError: true,
+		Tasks: []TaskRunner{
+			NewTask("t0", func(c TaskContext) error {
+				<-started
+				return errors.New("oh no")
+			}),
+			inner,
+		},
+	}
+
+	if err := g.Run(&taskContext{}); err == nil {
+		t.Error("expected an error")
+	}
+	if s := g.GetStatus(); s != TaskFailed {
+		t.Errorf("expected the group to be %q, got %q", TaskFailed, s)
+	}
+	if s := inner.GetStatus(); s != TaskCancelled {
+		t.Errorf("expected the inner group to be %q, got %q", TaskCancelled, s)
+	}
+	if s := inner.Tasks[0].GetStatus(); s != TaskCancelled {
+		t.Errorf("expected t2 to be %q, got %q", TaskCancelled, s)
+	}
+}
+
+func TestTaskGroup_Timing(t *testing.T) {
+	g := NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}),
+		NewTask("t1", func(c TaskContext) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}),
+	})
+	g.Run(&taskContext{})
+
+	states := g.GetTaskStates()
+	if states[0].Elapsed < 20*time.Millisecond {
+		t.Errorf("expected the group to take at least 20ms, got %s", states[0].Elapsed)
+	}
+	for _, s := range states[1:] {
+		if s.Elapsed < 10*time.Millisecond || s.Elapsed > states[0].Elapsed {
+			t.Errorf("expected task %q to take between 10ms and %s, got %s", s.Message, states[0].Elapsed, s.Elapsed)
+		}
+	}
+}
+
+func TestTaskGroup_Progress(t *testing.T) {
+	release := make(chan bool)
+	g := NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error { return nil }),
+		NewTask("t1", func(c TaskContext) error {
+			<-release
+			return nil
+		}),
+	})
+	if g.GetTaskStates()[0].Progress != nil {
+		t.Error("expected no progress before running")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- g.Run(&taskContext{})
+	}()
+	for g.Tasks[1].GetStatus() != TaskInProgress {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The progress is derived from the finished tasks...
+	p := g.GetTaskStates()[0].Progress
+	if p == nil || p.Current != 1 || p.Total != 2 {
+		t.Errorf("expected progress of 1/2, got %+v", p)
+	}
+
+	// ...unless it's set explicitly
+	g.SetProgress(7, 8)
+	p = g.GetTaskStates()[0].Progress
+	if p == nil || p.Current != 7 || p.Total != 8 {
+		t.Errorf("expected progress of 7/8, got %+v", p)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTaskGroup_Failures(t *testing.T) {
+	g := NewTaskGroup("g0", []TaskRunner{
+		NewTask("t0", func(c TaskContext) error {
+			<-c.Context().Done()
+			return c.Context().Err()
+		}),
+	})
+	g.Timeout = 10 * time.Millisecond
+	g.Run(&taskContext{})
+
+	fs := g.Failures()
+	if len(fs) != 2 {
+		t.Fatalf("expected 2 failures, got %d: %v", len(fs), fs)
+	}
+	if p := fmtPath(fs[0].Path); p != "g0" || fs[0].Status != TaskTimedOut || !errors.Is(fs[0], ErrTimedOut) {
+		t.Errorf("expected the group's timeout first, got %v (%s)", fs[0], fs[0].Status)
+	}
+	if p := fmtPath(fs[1].Path); p != "g0 › t0" {
+		t.Errorf("expected the task's path %q, got %q", "g0 › t0", p)
+	}
+}
@@ -0,0 +1,87 @@
+package golist
+
+import "strings"
+
+// pathSeparator separates the names of
+// tasks in a task's path (e.g. "deploy › api")
+const pathSeparator = " › "
+
+// taskNode is a TaskRunner in a List's tree of tasks
+type taskNode struct {
+	runner TaskRunner
+	parent TaskRunner // The TaskGroup the task belongs to, or nil if it's not in one
+	path   []string   // The names of the task's ancestors, followed by its own
+	state  *TaskState // The task's own state
+	err    error      // The task's own error, not including its subtasks' errors
+	group  bool       // Is the task a TaskGroup?
+}
+
+// walkTasks returns the TaskRunners in `ts` (and, for any
+// TaskGroups, their tasks) in tree order. `parent` is the
+// path of the TaskGroup that `ts` belong to, if any.
+func walkTasks(ts []TaskRunner, parent []string) []taskNode {
+	return walkGroup(ts, parent, nil)
+}
+
+// walkGroup is like `walkTasks`, for the tasks `ts` of the
+// TaskGroup `group` (or nil for tasks that aren't in a group)
+func walkGroup(ts []TaskRunner, parent []string, group TaskRunner) []taskNode {
+	var nodes []taskNode
+	for _, t := range ts {
+		states := t.GetTaskStates()
+		if len(states) == 0 {
+			continue
+		}
+		n := taskNode{
+			runner: t,
+			parent: group,
+			state:  states[0],
+			err:    t.GetError(),
+		}
+		n.path = append(append([]string{}, parent...), pathName(t))
+
+		tg, isGroup := t.(*TaskGroup)
+		if !isGroup {
+			nodes = append(nodes, n)
+			continue
+		}
+		n.group = true
+		n.err = tg.getOwnError()
+		nodes = append(nodes, n)
+		nodes = append(nodes, walkGroup(tg.Tasks, n.path, tg)...)
+	}
+	return nodes
+}
+
+// taskName returns the name used for a task in task
+// paths: its ID if it has one, otherwise its message
+func taskName(t TaskRunner) string {
+	if id := getID(t); id != "" {
+		return id
+	}
+	if m, ok := t.(interface{ GetMessage() string }); ok {
+		return m.GetMessage()
+	}
+	if states := t.GetTaskStates(); len(states) > 0 {
+		return states[0].Message
+	}
+	return ""
+}
+
+// pathName returns the name used for a task in the paths of a
+// run's results: the name it had when it last started, so that
+// the paths match the ones in the run's events even if the task
+// changed its message, otherwise its current name
+func pathName(t TaskRunner) string {
+	if n, ok := t.(interface{ getName() string }); ok {
+		if name := n.getName(); name != "" {
+			return name
+		}
+	}
+	return taskName(t)
+}
+
+// fmtPath formats a task path (e.g. "deploy › services › api")
+func fmtPath(path []string) string {
+	return strings.Join(path, pathSeparator)
+}
@@ -0,0 +1,78 @@
+package golist
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestWalkTasks(t *testing.T) {
+	err := errors.New("oops")
+	g := NewTaskGroup("services", []TaskRunner{
+		NewTask("api", func(c TaskContext) error { return err }),
+		NewTask("web", func(c TaskContext) error { return nil }),
+	})
+	g.ID = "svc"
+	ts := []TaskRunner{
+		NewTask("build", func(c TaskContext) error { return nil }),
+		g,
+	}
+	for _, task := range ts {
+		task.Run(&taskContext{})
+	}
+
+	nodes := walkTasks(ts, []string{"deploy"})
+	e := []string{
+		"deploy › build",
+		"deploy › svc",
+		"deploy › svc › api",
+		"deploy › svc › web",
+	}
+	if len(nodes) != len(e) {
+		t.Fatalf("expected %d nodes, got %d", len(e), len(nodes))
+	}
+	for i, n := range nodes {
+		if p := fmtPath(n.path); p != e[i] {
+			t.Errorf("expected path %q, got %q", e[i], p)
+		}
+	}
+
+	if !nodes[1].group || nodes[0].group {
+		t.Error("expected only the task group to be marked as a group")
+	}
+	if nodes[1].err != nil {
+		t.Errorf("expected the group to have no error of its own, got %v", nodes[1].err)
+	}
+	if nodes[2].err != err {
+		t.Errorf("expected the task's error %v, got %v", err, nodes[2].err)
+	}
+}
+
+func TestWalkTasks_MessageChanged(t *testing.T) {
+	l := NewList()
+	l.Writer = &bytes.Buffer{}
+	var failed Event
+	l.AddObserver(ObserverFunc(func(e Event) {
+		if e.Type == EventTaskFailed && e.Task != nil {
+			if _, ok := e.Task.(*Task); ok {
+				failed = e
+			}
+		}
+	}))
+	l.AddTask(NewTaskGroup("group", []TaskRunner{
+		NewTask("fetch", func(c TaskContext) error {
+			c.SetMessage("fetching 3/10")
+			return errors.New("oops")
+		}),
+	}))
+	l.RunAndWait()
+
+	// The failure's path matches its event's, from when it started
+	fs := l.Failures()
+	if len(fs) != 1 {
+		t.Fatalf("expected 1 failure, got %d", len(fs))
+	}
+	if p, e := fmtPath(fs[0].Path), fmtPath(failed.Path); p != e || p != "group › fetch" {
+		t.Errorf("expected the path %q from the event, got %q", e, p)
+	}
+}
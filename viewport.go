@@ -0,0 +1,131 @@
+package golist
+
+import "fmt"
+
+// fitHeight returns the task states to display so that they
+// fit in `height` lines. If they already fit (or if `height`
+// is 0) the states are returned unchanged.
+//
+// To make space, runs of completed tasks are collapsed into
+// summary rows (e.g. "… 42 completed"), longest runs first,
+// followed by runs of tasks that haven't started. If that's
+// still not enough, other rows are hidden from the top down
+// so that tasks that are running or have failed stay visible.
+func fitHeight(states []*TaskState, height int) []*TaskState {
+	if height <= 0 || len(states) <= height {
+		return states
+	}
+
+	states = collapseRuns(states, height, TaskCompleted, "completed")
+	states = collapseRuns(states, height, TaskNotStarted, "pending")
+	if len(states) <= height {
+		return states
+	}
+	return hideRows(states, height)
+}
+
+// collapseRuns collapses runs of consecutive states with the
+// status `s` into single summary rows, longest runs first,
+// until the states fit in `height` lines.
+func collapseRuns(states []*TaskState, height int, s TaskStatus, label string) []*TaskState {
+	for len(states) > height {
+		// Find the longest run of states with status `s`
+		var start, size int
+		for i := 0; i < len(states); {
+			j := i
+			for j < len(states) && states[j].Status == s && states[j].collapsed == 0 && !states[j].pinned() {
+				j++
+			}
+			if j-i > size {
+				start, size = i, j-i
+			}
+			if j == i {
+				j++
+			}
+			i = j
+		}
+		if size < 2 {
+			break
+		}
+
+		// Replace it with a summary row at the
+		// shallowest depth in the run
+		depth := states[start].Depth
+		for _, t := range states[start : start+size] {
+			if t.Depth < depth {
+				depth = t.Depth
+			}
+		}
+		row := &TaskState{
+			Message:   fmt.Sprintf("… %d %s", size, label),
+			Status:    s,
+			Depth:     depth,
+			collapsed: size,
+		}
+		collapsed := append([]*TaskState{}, states[:start]...)
+		collapsed = append(collapsed, row)
+		states = append(collapsed, states[start+size:]...)
+	}
+	return states
+}
+
+// hideRows hides rows from the top down, preferring to keep
+// rows for tasks that are running or have failed, and adds
+// a summary row at the top saying how many were hidden.
+func hideRows(states []*TaskState, height int) []*TaskState {
+	if height < 2 {
+		height = 2
+	}
+	keep := make([]bool, len(states))
+	n := height - 1 // Leave room for the summary row
+
+	// First, keep the important rows, from the bottom up
+	for i := len(states) - 1; i >= 0 && n > 0; i-- {
+		if isImportant(states[i].Status) || states[i].pinned() {
+			keep[i] = true
+			n--
+		}
+	}
+
+	// Then fill any remaining space with the other rows, from the bottom up
+	for i := len(states) - 1; i >= 0 && n > 0; i-- {
+		if !keep[i] {
+			keep[i] = true
+			n--
+		}
+	}
+
+	var hidden int
+	fit := []*TaskState{nil}
+	for i, t := range states {
+		if keep[i] {
+			fit = append(fit, t)
+		} else if t.collapsed > 0 {
+			hidden += t.collapsed
+		} else {
+			hidden++
+		}
+	}
+	fit[0] = &TaskState{
+		Message:   fmt.Sprintf("… %d more", hidden),
+		Status:    TaskNotStarted,
+		collapsed: hidden,
+	}
+	return fit
+}
+
+// pinned returns true for rows that an interactive List's user
+// asked to see: the selected task and the lines a task printed
+func (t *TaskState) pinned() bool {
+	return t.selected || t.logLine
+}
+
+// isImportant returns true for statuses that
+// should stay visible when rows are hidden
+func isImportant(s TaskStatus) bool {
+	switch s {
+	case TaskInProgress, TaskFailed, TaskTimedOut, TaskCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package golist
+
+import "testing"
+
+func statesWith(ss ...TaskStatus) []*TaskState {
+	states := make([]*TaskState, len(ss))
+	for i, s := range ss {
+		states[i] = &TaskState{Message: "t", Status: s}
+	}
+	return states
+}
+
+func messages(states []*TaskState) []string {
+	ms := make([]string, len(states))
+	for i, s := range states {
+		ms[i] = s.Message
+	}
+	return ms
+}
+
+func TestFitHeight_Fits(t *testing.T) {
+	states := statesWith(TaskCompleted, TaskCompleted, TaskInProgress)
+	if got := fitHeight(states, 3); len(got) != 3 {
+		t.Errorf("expected 3 states, got %d", len(got))
+	}
+	if got := fitHeight(states, 0); len(got) != 3 {
+		t.Errorf("expected 3 states with no height limit, got %d", len(got))
+	}
+}
+
+func TestFitHeight_CollapseCompleted(t *testing.T) {
+	states := statesWith(
+		TaskCompleted, TaskCompleted, TaskCompleted, TaskCompleted,
+		TaskInProgress,
+		TaskNotStarted, TaskNotStarted,
+	)
+	got := fitHeight(states, 4)
+	e := []string{"… 4 completed", "t", "t", "t"}
+	if ms := messages(got); len(ms) != len(e) || ms[0] != e[0] {
+		t.Fatalf("expected %q, got %q", e, ms)
+	}
+	if got[1].Status != TaskInProgress {
+		t.Errorf("expected the running task to stay visible, got %q", got[1].Status)
+	}
+}
+
+func TestFitHeight_CollapsePending(t *testing.T) {
+	states := statesWith(
+		TaskCompleted, TaskCompleted,
+		TaskInProgress,
+		TaskNotStarted, TaskNotStarted, TaskNotStarted,
+	)
+	got := fitHeight(states, 3)
+	e := []string{"… 2 completed", "t", "… 3 pending"}
+	ms := messages(got)
+	if len(ms) != len(e) {
+		t.Fatalf("expected %q, got %q", e, ms)
+	}
+	for i := range e {
+		if ms[i] != e[i] {
+			t.Errorf("expected %q, got %q", e, ms)
+			break
+		}
+	}
+}
+
+func TestFitHeight_HideRows(t *testing.T) {
+	// Completed runs are broken up, so
+	// collapsing them isn't enough
+	states := statesWith(
+		TaskCompleted, TaskFailed,
+		TaskCompleted, TaskInProgress,
+		TaskCompleted, TaskInProgress,
+		TaskCompleted,
+	)
+	got := fitHeight(states, 4)
+	if len(got) != 4 {
+		t.Fatalf("expected 4 states, got %d", len(got))
+	}
+	if got[0].Message != "… 4 more" {
+		t.Errorf("expected summary row %q, got %q", "… 4 more", got[0].Message)
+	}
+	for _, s := range got[1:] {
+		if !isImportant(s.Status) {
+			t.Errorf("expected only important rows to be kept, got %q", s.Status)
+		}
+	}
+}
+
+func TestFitHeight_HideCollapsedRows(t *testing.T) {
+	states := statesWith(
+		TaskCompleted, TaskCompleted, TaskCompleted,
+		TaskInProgress, TaskInProgress, TaskInProgress,
+	)
+	got := fitHeight(states, 3)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 states, got %d", len(got))
+	}
+	// The collapsed row and one running task are hidden
+	if got[0].Message != "… 4 more" {
+		t.Errorf("expected summary row %q, got %q", "… 4 more", got[0].Message)
+	}
+}
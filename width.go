@@ -0,0 +1,98 @@
+package golist
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// AutoLineLength can be used as a List's MaxLineLength to
+// fit lines to the terminal's width. The width is re-read
+// whenever the terminal is resized.
+const AutoLineLength = -1
+
+// OverflowMode represents what a List does with
+// lines that are longer than its MaxLineLength
+type OverflowMode int
+
+const (
+	OverflowTruncate OverflowMode = iota // OverflowTruncate cuts long lines short, ending them with an ellipsis ("…")
+	OverflowWrap                         // OverflowWrap soft-wraps long lines onto indented continuation lines
+)
+
+// ansiPrefix matches an ANSI escape sequence
+// at the start of a string
+var ansiPrefix = regexp.MustCompile("^" + ansiEscape.String())
+
+// cellWidth returns the number of terminal cells `s` takes
+// up when it's printed, ignoring ANSI escape sequences. Wide
+// characters (like CJK characters and most emoji) take up
+// two cells.
+func cellWidth(s string) int {
+	return runewidth.StringWidth(stripANSI(s))
+}
+
+// splitCells splits `s` into rows that are each at most
+// `width` cells wide, the same way a terminal would wrap it.
+// ANSI escape sequences are kept but don't count towards the
+// width. If `width` is 0, `s` is returned as-is.
+func splitCells(s string, width int) []string {
+	if width <= 0 || cellWidth(s) <= width {
+		return []string{s}
+	}
+
+	var rows []string
+	var b strings.Builder
+	var w int
+	for len(s) > 0 {
+		if m := ansiPrefix.FindString(s); m != "" {
+			b.WriteString(m)
+			s = s[len(m):]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		rw := runewidth.RuneWidth(r)
+		if w > 0 && w+rw > width {
+			rows = append(rows, b.String())
+			b.Reset()
+			w = 0
+		}
+		b.WriteRune(r)
+		w += rw
+		s = s[size:]
+	}
+	return append(rows, b.String())
+}
+
+// wrapText soft-wraps `s` into lines that are each at most
+// `width` cells wide, breaking between words where possible.
+// Words that are too long for a line of their own are split.
+func wrapText(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case cellWidth(line)+1+cellWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+
+		// Split words that don't fit on a line
+		if cellWidth(line) > width {
+			rows := splitCells(line, width)
+			lines = append(lines, rows[:len(rows)-1]...)
+			line = rows[len(rows)-1]
+		}
+	}
+	return append(lines, line)
+}
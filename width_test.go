@@ -0,0 +1,62 @@
+package golist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCellWidth(t *testing.T) {
+	cases := []struct {
+		s string
+		e int
+	}{
+		{"hello", 5},
+		{"日本語", 6},
+		{"\033[32m✓\033[0m done", 6},
+		{"", 0},
+	}
+	for _, c := range cases {
+		if n := cellWidth(c.s); n != c.e {
+			t.Errorf("expected width of %q to be %d, got %d", c.s, c.e, n)
+		}
+	}
+}
+
+func TestSplitCells(t *testing.T) {
+	if rows := splitCells("abcdefg", 3); strings.Join(rows, "|") != "abc|def|g" {
+		t.Errorf("expected %q, got %q", "abc|def|g", rows)
+	}
+
+	// Wide characters aren't split across rows
+	if rows := splitCells("日本語", 5); strings.Join(rows, "|") != "日本|語" {
+		t.Errorf("expected %q, got %q", "日本|語", rows)
+	}
+
+	// Escape sequences don't take up any space
+	rows := splitCells("\033[31mabcd\033[0m", 2)
+	if len(rows) != 2 || stripANSI(rows[0]) != "ab" || stripANSI(rows[1]) != "cd" {
+		t.Errorf("expected rows %q and %q, got %q", "ab", "cd", rows)
+	}
+
+	if rows := splitCells("abc", 0); len(rows) != 1 {
+		t.Errorf("expected no split with width 0, got %q", rows)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+		e     string
+	}{
+		{"the quick brown fox", 10, "the quick|brown fox"},
+		{"the quick brown fox", 100, "the quick brown fox"},
+		{"abcdefghij klm", 4, "abcd|efgh|ij|klm"},
+		{"日本語のテキスト", 6, "日本語|のテキ|スト"},
+	}
+	for _, c := range cases {
+		if lines := strings.Join(wrapText(c.s, c.width), "|"); lines != c.e {
+			t.Errorf("expected %q wrapped to %d to be %q, got %q", c.s, c.width, c.e, lines)
+		}
+	}
+}